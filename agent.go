@@ -0,0 +1,135 @@
+package robotstxt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AgentMatchMode selects how FindGroup picks the group of an agent, see
+// RobotsData.AgentMatchMode.
+type AgentMatchMode int
+
+const (
+	// AgentMatchToken matches the ProductToken of the agent to user-agent
+	// names exactly, ignoring case. From RFC 9309 section 2.2.1:
+	// Crawlers MUST use case-insensitive matching to find the group that
+	// matches the product token and then obey the rules of the group.
+	AgentMatchToken AgentMatchMode = iota
+
+	// AgentMatchPrefix picks the longest user-agent name that is a prefix of
+	// the whole agent in lower case, as this package always did. A group
+	// named "foo" matches "FooBarBot" then.
+	AgentMatchPrefix
+)
+
+func (m AgentMatchMode) String() string {
+	switch m {
+	case AgentMatchToken:
+		return "token"
+	case AgentMatchPrefix:
+		return "prefix"
+	}
+	return fmt.Sprintf("AgentMatchMode(%d)", int(m))
+}
+
+// ProductToken returns the name of the crawler in a User-Agent header,
+// without its version: "FooBot" for "FooBot/2.1" and for
+// "Mozilla/5.0 (compatible; FooBot/2.1; +http://example.com/bot)". The
+// product after "compatible" in a comment is preferred, then the first
+// product other than "Mozilla".
+func ProductToken(userAgent string) string {
+	var products []string
+	compatible := ""
+	rest := userAgent
+	for rest != "" {
+		rest = strings.TrimLeftFunc(rest, isSpace)
+		if strings.HasPrefix(rest, "(") {
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				end = len(rest)
+			}
+			if compatible == "" {
+				compatible = compatibleProduct(rest[1:end])
+			}
+			if end < len(rest) {
+				end++
+			}
+			rest = rest[end:]
+			continue
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool { return r == '(' || isSpace(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		if name := productName(rest[:end]); name != "" {
+			products = append(products, name)
+		}
+		rest = rest[end:]
+	}
+
+	if compatible != "" {
+		return compatible
+	}
+	for _, p := range products {
+		if !strings.EqualFold(p, "mozilla") {
+			return p
+		}
+	}
+	if len(products) > 0 {
+		return products[0]
+	}
+	return ""
+}
+
+// compatibleProduct returns the product after "compatible" in the items of
+// a comment, as in "compatible; FooBot/2.1; +http://example.com/bot".
+func compatibleProduct(comment string) string {
+	items := strings.Split(comment, ";")
+	for i, item := range items {
+		if !strings.EqualFold(strings.TrimSpace(item), "compatible") {
+			continue
+		}
+		for _, next := range items[i+1:] {
+			next = strings.TrimSpace(next)
+			if strings.HasPrefix(next, "+") || strings.Contains(next, "://") {
+				continue
+			}
+			if name := productName(next); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// productName returns the name of a product, the text before its version.
+func productName(product string) string {
+	if i := strings.IndexByte(product, '/'); i >= 0 {
+		product = product[:i]
+	}
+	return strings.TrimFunc(product, isSpace)
+}
+
+// findGroupByToken returns the group whose user-agent is the product token
+// of agent, see AgentMatchToken.
+func (r *RobotsData) findGroupByToken(agent string) (string, *Group) {
+	token := strings.ToLower(ProductToken(agent))
+	if token == "" || token == AnyGroupId {
+		return "", nil
+	}
+	if g := r.groups[token]; g != nil {
+		return token, g
+	}
+	// A user-agent line may name a version or a whole header too, as in
+	// "FooBot/2.1".
+	id := ""
+	for a := range r.groups {
+		if a != AnyGroupId && strings.ToLower(ProductToken(a)) == token && (id == "" || a < id) {
+			id = a
+		}
+	}
+	if id == "" {
+		return "", nil
+	}
+	return id, r.groups[id]
+}
@@ -0,0 +1,99 @@
+package robotstxt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductToken(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		ua     string
+		expect string
+	}{
+		{"", ""},
+		{"FooBot", "FooBot"},
+		{"FooBot/2.1", "FooBot"},
+		{"  FooBot/2.1 (+http://example.com/bot)", "FooBot"},
+		{"Mozilla/5.0 (compatible; FooBot/2.1; +http://example.com/bot)", "FooBot"},
+		{"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)", "bingbot"},
+		{"Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; Googlebot/2.1; +http://www.google.com/bot.html) Chrome/120.0.0.0 Safari/537.36", "Googlebot"},
+		{"Mozilla/5.0 (compatible; +http://example.com/bot; FooBot)", "FooBot"},
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", "facebookexternalhit"},
+		{"Mozilla/5.0 (X11; Linux x86_64) curl/8.0", "curl"},
+		{"Mozilla/5.0 (X11; Linux x86_64", "Mozilla"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, ProductToken(c.ua), "%q", c.ua)
+	}
+}
+
+func TestAgentMatchMode(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`User-agent: *
+Disallow: /
+
+User-agent: foo
+Disallow: /foo
+
+User-agent: FooBot
+Disallow: /foobot
+
+User-agent: BarBot/1.0
+Disallow: /barbot
+`)
+	require.NoError(t, err)
+	assert.Equal(t, AgentMatchToken, r.AgentMatchMode)
+
+	const header = "Mozilla/5.0 (compatible; FooBot/2.1; +http://example.com/bot)"
+	cases := []struct {
+		agent  string
+		token  string
+		prefix string
+	}{
+		{header, "foobot", "*"},
+		{"FOOBOT", "foobot", "foobot"},
+		{"FooBarBot", "*", "foo"},
+		{"Foo", "foo", "foo"},
+		{"BarBot/2.0", "barbot/1.0", "*"},
+		{"Baz", "*", "*"},
+		{"*", "*", "*"},
+		{"", "*", "*"},
+	}
+	for _, c := range cases {
+		id, _ := r.FindGroupWithGroupId(c.agent)
+		assert.Equal(t, c.token, id, "token %q", c.agent)
+	}
+	assert.False(t, r.TestAgent("/foobot", header))
+	assert.True(t, r.TestAgent("/foo", header))
+
+	r.AgentMatchMode = AgentMatchPrefix
+	for _, c := range cases {
+		id, _ := r.FindGroupWithGroupId(c.agent)
+		assert.Equal(t, c.prefix, id, "prefix %q", c.agent)
+	}
+
+	// The mode survives JSON.
+	b, err := json.Marshal(r)
+	require.NoError(t, err)
+	var r2 RobotsData
+	require.NoError(t, json.Unmarshal(b, &r2))
+	assert.Equal(t, AgentMatchPrefix, r2.AgentMatchMode)
+	assert.Equal(t, "prefix", r2.AgentMatchMode.String())
+	r.AgentMatchMode = AgentMatchToken
+	b, err = json.Marshal(r)
+	require.NoError(t, err)
+	r2 = RobotsData{}
+	require.NoError(t, json.Unmarshal(b, &r2))
+	assert.Equal(t, AgentMatchToken, r2.AgentMatchMode)
+
+	// JSON from before the mode existed keeps prefix matching.
+	r2 = RobotsData{}
+	require.NoError(t, json.Unmarshal([]byte(`{"groups": {"foo": {"agent": "foo"}}}`), &r2))
+	assert.Equal(t, AgentMatchPrefix, r2.AgentMatchMode)
+	id, _ := r2.FindGroupWithGroupId("FooBarBot")
+	assert.Equal(t, "foo", id)
+}
@@ -0,0 +1,285 @@
+// Package ast is a document model of robots.txt files.
+//
+// Unlike robotstxt.RobotsData, which only keeps what matters for crawling
+// decisions, a File keeps every byte of the source: order of lines,
+// comments, blank lines, unknown directives and the exact spelling and
+// spacing of each line. Printing a parsed File gives back the source byte
+// for byte, which makes it suitable for tools that edit robots.txt files.
+package ast
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pos is a position in the source. Offset is 0-based and counts bytes, Line
+// and Column are 1-based and Column counts characters.
+type Pos struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Kind classifies a Line.
+type Kind int
+
+const (
+	// Blank is an empty line or a line of whitespace.
+	Blank Kind = iota
+	// CommentLine has nothing but a comment.
+	CommentLine
+	// Directive is a "key: value" line.
+	Directive
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Blank:
+		return "Blank"
+	case CommentLine:
+		return "CommentLine"
+	case Directive:
+		return "Directive"
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Comment is a "#" comment up to the end of a line.
+type Comment struct {
+	Pos  Pos
+	Text string // Including the leading "#"
+}
+
+// Line is a physical line of a robots.txt file, split into the pieces that
+// are concatenated when the line is printed:
+//
+//	Indent Markup Key Sep Value Space Comment EOL
+type Line struct {
+	Pos     Pos
+	Kind    Kind
+	Indent  string   // Whitespace at the start of the line
+	Markup  string   // HTML markup before the key, ignored by parsers
+	Key     string   // Directive name as written
+	Sep     string   // Colon and whitespace around it, or only whitespace
+	Value   string   // Directive value, trimmed
+	Space   string   // Whitespace after the value
+	Comment *Comment // Trailing comment, or nil
+	EOL     string   // "\n", "\r\n", "\r" or "" at the end of the file
+}
+
+// NewDirective returns a directive line "key: value", ready to be inserted
+// into a File.
+func NewDirective(key, value string) *Line {
+	sep := ":"
+	if value != "" {
+		sep = ": "
+	}
+	return &Line{Kind: Directive, Key: key, Sep: sep, Value: value, EOL: "\n"}
+}
+
+// Name returns the directive name in lower case, "" for other kinds.
+func (l *Line) Name() string {
+	if l.Kind != Directive {
+		return ""
+	}
+	return strings.ToLower(l.Key)
+}
+
+// SetValue replaces the value of a directive line, keeping its spacing and
+// comment.
+func (l *Line) SetValue(value string) {
+	if l.Value == "" && value != "" && !strings.HasSuffix(l.Sep, " ") {
+		l.Sep += " "
+	}
+	l.Value = value
+}
+
+// String returns the line as it is printed, without the line ending.
+func (l *Line) String() string {
+	var b strings.Builder
+	l.write(&b)
+	return b.String()
+}
+
+func (l *Line) write(w io.StringWriter) {
+	w.WriteString(l.Indent)
+	w.WriteString(l.Markup)
+	w.WriteString(l.Key)
+	w.WriteString(l.Sep)
+	w.WriteString(l.Value)
+	w.WriteString(l.Space)
+	if l.Comment != nil {
+		w.WriteString(l.Comment.Text)
+	}
+}
+
+// Group is a run of User-agent lines and the member lines that follow them.
+// Members are Allow, Disallow and any other directives except Sitemap and
+// Host, which are not part of any group. Member lines before the first
+// User-agent line form a group without Agents.
+type Group struct {
+	Pos     Pos
+	Agents  []*Line
+	Members []*Line
+}
+
+// File is a parsed robots.txt file.
+type File struct {
+	BOM   bool // Whether the source started with a UTF-8 byte order mark
+	Lines []*Line
+}
+
+// Bytes returns the file as robots.txt text. For a File returned by Parse
+// and not modified since, this is the source it was parsed from.
+func (f *File) Bytes() []byte {
+	var b bytes.Buffer
+	if f.BOM {
+		b.WriteString(bom)
+	}
+	for _, l := range f.Lines {
+		l.write(&b)
+		b.WriteString(l.EOL)
+	}
+	return b.Bytes()
+}
+
+// WriteTo writes the file as robots.txt text, see Bytes.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(f.Bytes())
+	return int64(n), err
+}
+
+// Directives returns the directive lines in source order.
+func (f *File) Directives() []*Line {
+	var ls []*Line
+	for _, l := range f.Lines {
+		if l.Kind == Directive {
+			ls = append(ls, l)
+		}
+	}
+	return ls
+}
+
+// ExtensionFunc tells Groups about extension directives, which only the
+// caller knows. It is called with the lower case name of directives that
+// are not built in, and reports whether the name is an extension and
+// whether it applies to the whole file instead of a group.
+type ExtensionFunc func(name string) (ok, global bool)
+
+// Groups returns the groups of the file, computed from its current lines.
+// Directives that are not built in are treated as unknown, see GroupsWith.
+func (f *File) Groups() []*Group {
+	return f.GroupsWith(nil)
+}
+
+// GroupsWith returns the groups of the file like Groups, with the extension
+// directives reported by ext. Group extensions end the User-agent lines of
+// a group like rules, global ones are not part of any group, like Sitemap.
+// A nil ext knows no extensions.
+func (f *File) GroupsWith(ext ExtensionFunc) []*Group {
+	var (
+		groups []*Group
+		g      *Group
+		ruled  bool // Whether g has a rule, so that a User-agent starts a new group
+	)
+	for _, l := range f.Lines {
+		switch name := l.Name(); {
+		case name == "":
+			continue
+		case isUserAgent(name):
+			// Two successive user-agent lines are part of the same group.
+			if g == nil || ruled {
+				g = &Group{Pos: l.Pos}
+				groups = append(groups, g)
+				ruled = false
+			}
+			g.Agents = append(g.Agents, l)
+		case name == "sitemap" || name == "host":
+			continue
+		default:
+			known, global := isRule(name), false
+			if !known && ext != nil {
+				known, global = ext(name)
+			}
+			if known && global {
+				continue
+			}
+			if g == nil {
+				g = &Group{Pos: l.Pos}
+				groups = append(groups, g)
+			}
+			g.Members = append(g.Members, l)
+			// Unknown directives do not end the list of agents, the same
+			// way the robotstxt parser handles them.
+			ruled = ruled || known
+		}
+	}
+	return groups
+}
+
+// Index returns the index of l in f.Lines, or -1.
+func (f *File) Index(l *Line) int {
+	for i, x := range f.Lines {
+		if x == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// InsertAfter inserts lines after the line at, or at the start of the file
+// if at is nil. Inserted lines have no position.
+func (f *File) InsertAfter(at *Line, lines ...*Line) {
+	i := 0
+	if at != nil {
+		if i = f.Index(at) + 1; i == 0 {
+			i = len(f.Lines)
+		}
+	}
+	// The line before the insertion point must end, or the new lines would
+	// be glued to it when printed.
+	if i > 0 && f.Lines[i-1].EOL == "" {
+		f.Lines[i-1].EOL = "\n"
+	}
+	f.Lines = append(f.Lines[:i], append(lines, f.Lines[i:]...)...)
+}
+
+// Append adds lines to the end of the file.
+func (f *File) Append(lines ...*Line) {
+	var last *Line
+	if len(f.Lines) > 0 {
+		last = f.Lines[len(f.Lines)-1]
+	}
+	f.InsertAfter(last, lines...)
+}
+
+// Remove deletes l from the file and reports whether it was found.
+func (f *File) Remove(l *Line) bool {
+	i := f.Index(l)
+	if i < 0 {
+		return false
+	}
+	f.Lines = append(f.Lines[:i], f.Lines[i+1:]...)
+	return true
+}
+
+// isRule reports whether a built-in directive is a group member that ends
+// the list of agents. The names follow the key table of the robotstxt parser.
+func isRule(name string) bool {
+	switch name {
+	case "allow", "disallow", "crawl-delay", "crawldelay", "request-rate", "requestrate", "visit-time", "visittime", "clean-param", "cleanparam", "clean-params",
+		"content-signal", "content-usage":
+		return true
+	}
+	return false
+}
+
+func isUserAgent(name string) bool {
+	switch name {
+	case "user-agent", "useragent", "usser-agent", "ser-agent":
+		return true
+	}
+	return false
+}
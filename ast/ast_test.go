@@ -0,0 +1,181 @@
+package ast
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const robotsCommented = "\xef\xbb\xbf# Robots for example.com\r\n" +
+	"User-agent: Googlebot   # Google\r\n" +
+	"user-agent :bingbot\r\n" +
+	"Disallow: /private\r\n" +
+	"\r\n" +
+	"  Noindex: /drafts\n" +
+	"<!DOCTYPE html>\n" +
+	"User-agent: *\n" +
+	"Disallow /tmp\n" +
+	"Sitemap: https://example.com/sitemap.xml\n" +
+	"Allow:\t/\t# everything else"
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	inputs := map[string]string{
+		"empty":     "",
+		"newline":   "\n",
+		"cr":        "a: b\rc: d\r",
+		"mixed":     "a: b\r\n\n\r\n\rc",
+		"commented": robotsCommented,
+		"invalid":   "User-agent: H\xef\xbf\xbdm\xd9\xd9\nDisallow: *",
+		"html":      "<html>\n<title></title>\n<p>Hello world!</p>\n",
+		"spaces":    " \t \n\v# x \t\n",
+	}
+	corpus, err := filepath.Glob("../_gofuzz/corpus/*")
+	require.NoError(t, err)
+	for _, name := range corpus {
+		buf, err := os.ReadFile(name)
+		require.NoError(t, err)
+		inputs[filepath.Base(name)] = string(buf)
+	}
+
+	for name, input := range inputs {
+		f := Parse([]byte(input))
+		assert.Equal(t, input, string(f.Bytes()), name)
+
+		var b bytes.Buffer
+		n, err := f.WriteTo(&b)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(input)), n)
+	}
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+	f := Parse([]byte(robotsCommented))
+	require.True(t, f.BOM)
+	require.Len(t, f.Lines, 11)
+
+	type expect struct {
+		kind    Kind
+		key     string
+		value   string
+		comment string
+		pos     Pos
+	}
+	cases := []expect{
+		{CommentLine, "", "", "# Robots for example.com", Pos{3, 1, 1}},
+		{Directive, "User-agent", "Googlebot", "# Google", Pos{29, 2, 1}},
+		{Directive, "user-agent", "bingbot", "", Pos{63, 3, 1}},
+		{Directive, "Disallow", "/private", "", Pos{84, 4, 1}},
+		{Blank, "", "", "", Pos{104, 5, 1}},
+		{Directive, "Noindex", "/drafts", "", Pos{108, 6, 3}},
+		{Blank, "", "", "", Pos{125, 7, 1}},
+		{Directive, "User-agent", "*", "", Pos{141, 8, 1}},
+		{Directive, "Disallow", "/tmp", "", Pos{155, 9, 1}},
+		{Directive, "Sitemap", "https://example.com/sitemap.xml", "", Pos{169, 10, 1}},
+		{Directive, "Allow", "/", "# everything else", Pos{210, 11, 1}},
+	}
+	for i, c := range cases {
+		l := f.Lines[i]
+		assert.Equal(t, c.kind, l.Kind, "line %d", i+1)
+		assert.Equal(t, c.key, l.Key, "line %d", i+1)
+		assert.Equal(t, c.value, l.Value, "line %d", i+1)
+		assert.Equal(t, c.pos, l.Pos, "line %d", i+1)
+		if c.comment == "" {
+			assert.Nil(t, l.Comment, "line %d", i+1)
+		} else if assert.NotNil(t, l.Comment, "line %d", i+1) {
+			assert.Equal(t, c.comment, l.Comment.Text)
+		}
+	}
+
+	assert.Equal(t, " :", f.Lines[2].Sep)
+	assert.Equal(t, " ", f.Lines[8].Sep)
+	assert.Equal(t, "<!DOCTYPE html>", f.Lines[6].Markup)
+	assert.Equal(t, Pos{53, 2, 25}, f.Lines[1].Comment.Pos)
+	assert.Equal(t, "\r\n", f.Lines[0].EOL)
+	assert.Equal(t, "", f.Lines[10].EOL)
+}
+
+func TestGroups(t *testing.T) {
+	t.Parallel()
+	f := Parse([]byte(`Disallow: /before
+User-agent: a
+Noindex: /x
+User-agent: b
+Disallow: /a
+Sitemap: https://example.com/sitemap.xml
+Crawl-delay: 5
+
+User-agent: c
+`))
+	groups := f.Groups()
+	require.Len(t, groups, 3)
+
+	assert.Empty(t, groups[0].Agents)
+	assert.Equal(t, []string{"/before"}, values(groups[0].Members))
+
+	assert.Equal(t, []string{"a", "b"}, values(groups[1].Agents))
+	assert.Equal(t, []string{"/x", "/a", "5"}, values(groups[1].Members))
+	assert.Equal(t, 2, groups[1].Pos.Line)
+
+	assert.Equal(t, []string{"c"}, values(groups[2].Agents))
+	assert.Empty(t, groups[2].Members)
+}
+
+func TestGroupsWith(t *testing.T) {
+	t.Parallel()
+	f := Parse([]byte(`User-agent: a
+Noindex: /x
+Host-load: 2
+User-agent: b
+Disallow: /b
+`))
+	ext := func(name string) (ok, global bool) {
+		return name == "noindex" || name == "host-load", name == "host-load"
+	}
+	groups := f.GroupsWith(ext)
+	require.Len(t, groups, 2)
+	assert.Equal(t, []string{"a"}, values(groups[0].Agents))
+	assert.Equal(t, []string{"/x"}, values(groups[0].Members))
+	assert.Equal(t, []string{"b"}, values(groups[1].Agents))
+	assert.Equal(t, []string{"/b"}, values(groups[1].Members))
+
+	// Without extensions both are unknown and the agents share a group.
+	groups = f.Groups()
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{"a", "b"}, values(groups[0].Agents))
+	assert.Equal(t, []string{"/x", "2", "/b"}, values(groups[0].Members))
+}
+
+func TestEdit(t *testing.T) {
+	t.Parallel()
+	f := Parse([]byte("# keep me\nUser-agent: *\nDisallow: /old # legacy\nDisallow: /gone"))
+	groups := f.Groups()
+	require.Len(t, groups, 1)
+
+	groups[0].Members[0].SetValue("/new")
+	require.True(t, f.Remove(groups[0].Members[1]))
+	f.InsertAfter(groups[0].Agents[0], NewDirective("Allow", "/public"))
+	f.Append(NewDirective("Sitemap", "https://example.com/sitemap.xml"))
+	f.InsertAfter(nil, NewDirective("Host", "example.com"))
+
+	assert.Equal(t, "Host: example.com\n# keep me\nUser-agent: *\nAllow: /public\nDisallow: /new # legacy\nSitemap: https://example.com/sitemap.xml\n", string(f.Bytes()))
+	assert.False(t, f.Remove(NewDirective("Allow", "/")))
+
+	l := NewDirective("Disallow", "")
+	assert.Equal(t, "Disallow:", l.String())
+	l.SetValue("/x")
+	assert.Equal(t, "Disallow: /x", l.String())
+}
+
+func values(lines []*Line) []string {
+	var vs []string
+	for _, l := range lines {
+		vs = append(vs, l.Value)
+	}
+	return vs
+}
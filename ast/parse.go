@@ -0,0 +1,121 @@
+package ast
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const bom = "\xef\xbb\xbf"
+
+// Parse splits robots.txt source into lines. It never fails: text that is
+// not a directive is still kept, so that the file prints back unchanged.
+// Lines are split the same way the robotstxt package reads them.
+func Parse(src []byte) *File {
+	f := &File{}
+	s := string(src)
+	if strings.HasPrefix(s, bom) {
+		f.BOM = true
+		s = s[len(bom):]
+	}
+
+	pos := Pos{Offset: len(src) - len(s), Line: 1, Column: 1}
+	for len(s) > 0 {
+		text, eol := s, ""
+		if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+			text = s[:i]
+			eol = s[i : i+1]
+			if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				eol = "\r\n"
+			}
+		}
+		l := parseLine(text, pos)
+		l.EOL = eol
+		f.Lines = append(f.Lines, l)
+
+		n := len(text) + len(eol)
+		s = s[n:]
+		pos = Pos{Offset: pos.Offset + n, Line: pos.Line + 1, Column: 1}
+	}
+	return f
+}
+
+func parseLine(text string, pos Pos) *Line {
+	l := &Line{Pos: pos}
+	rest := text
+
+	l.Indent, rest = splitSpace(rest)
+	// HTML markup at the start of a line, see byteScanner.skipTag.
+	for strings.HasPrefix(rest, "<") {
+		n := markupLen(rest)
+		sp, _ := splitSpace(rest[n:])
+		l.Markup += rest[:n+len(sp)]
+		rest = rest[n+len(sp):]
+	}
+
+	content := rest
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		content = rest[:i]
+		l.Comment = &Comment{
+			Pos:  advance(pos, text[:len(text)-len(rest)+i]),
+			Text: rest[i:],
+		}
+	}
+	trimmed := strings.TrimRightFunc(content, isSpace)
+	l.Space = content[len(trimmed):]
+
+	switch {
+	case trimmed == "" && l.Comment == nil:
+		l.Kind = Blank
+		// Keep markup-only lines printable, they have no key to attach to.
+		return l
+	case trimmed == "":
+		l.Kind = CommentLine
+		return l
+	}
+
+	l.Kind = Directive
+	l.Pos = advance(pos, text[:len(text)-len(rest)])
+	if i := strings.IndexByte(trimmed, ':'); i >= 0 {
+		l.Key = strings.TrimRightFunc(trimmed[:i], isSpace)
+		l.Value = strings.TrimLeftFunc(trimmed[i+1:], isSpace)
+	} else if i := strings.IndexFunc(trimmed, isSpace); i >= 0 {
+		l.Key = trimmed[:i]
+		l.Value = strings.TrimLeftFunc(trimmed[i:], isSpace)
+	} else {
+		l.Key = trimmed
+	}
+	l.Sep = trimmed[len(l.Key) : len(trimmed)-len(l.Value)]
+	return l
+}
+
+// markupLen returns the length of the tag at the start of s, including the
+// element content up to another tag on the same line.
+func markupLen(s string) int {
+	i := strings.IndexByte(s, '>')
+	if i < 0 {
+		return len(s)
+	}
+	i++
+	if i < len(s) && s[i] != '<' {
+		if j := strings.IndexByte(s[i:], '<'); j >= 0 {
+			i += j
+		}
+	}
+	return i
+}
+
+func splitSpace(s string) (space, rest string) {
+	rest = strings.TrimLeftFunc(s, isSpace)
+	return s[:len(s)-len(rest)], rest
+}
+
+// Same whitespace as robotstxt.WhitespaceChars.
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\v'
+}
+
+func advance(pos Pos, s string) Pos {
+	pos.Offset += len(s)
+	pos.Column += utf8.RuneCountInString(s)
+	return pos
+}
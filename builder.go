@@ -0,0 +1,287 @@
+package robotstxt
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Builder assembles RobotsData in code, as an alternative to generating
+// robots.txt text and parsing it:
+//
+//	r, err := robotstxt.NewBuilder().
+//		Group("Googlebot", "Bingbot").Disallow("/private").Allow("/private/open").
+//		Group("*").Disallow("/tmp").CrawlDelay(2 * time.Second).
+//		Sitemap("https://example.com/sitemap.xml").
+//		Build()
+//
+// Values are checked as they are added. Problems are collected and returned
+// by Build and Text, so a chain of calls needs only one error check.
+type Builder struct {
+	groups   map[string]*Group
+	agents   []string // Agents of the current group
+	host     string
+	sitemaps []string
+	errs     []error
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{groups: make(map[string]*Group, 4)}
+}
+
+// Group starts a new group for the given agents, the members added after it
+// apply to all of them. As in a parsed file, naming an agent in more than one
+// group merges the groups.
+func (b *Builder) Group(agents ...string) *Builder {
+	b.agents = make([]string, 0, len(agents))
+	if len(agents) == 0 {
+		b.errorf("Group without agents")
+	}
+	for _, a := range agents {
+		if a == "" || strings.IndexFunc(a, isSpace) >= 0 || strings.ContainsAny(a, "#\r\n") {
+			b.errorf("User-agent %q is not a product token", a)
+			continue
+		}
+		a = strings.ToLower(a)
+		if !containsString(b.agents, a) {
+			b.agents = append(b.agents, a)
+		}
+	}
+	parseGroupMap(b.groups, b.agents, func(*Group) {})
+	return b
+}
+
+// Allow adds Allow rules with the given path patterns to the current group.
+func (b *Builder) Allow(patterns ...string) *Builder {
+	return b.addRules("Allow", true, patterns)
+}
+
+// Disallow adds Disallow rules with the given path patterns to the current
+// group. An empty pattern disallows nothing, as in a parsed file.
+func (b *Builder) Disallow(patterns ...string) *Builder {
+	return b.addRules("Disallow", false, patterns)
+}
+
+func (b *Builder) addRules(key string, allow bool, patterns []string) *Builder {
+	if !b.inGroup(key) {
+		return b
+	}
+	for _, p := range patterns {
+		li, err := b.pathVal(key, p)
+		if err != nil || li.raw == "" {
+			continue
+		}
+		r := &rule{path: li.vs, allow: allow, pattern: li.vr, raw: li.raw}
+		parseGroupMap(b.groups, b.agents, func(g *Group) { g.rules = append(g.rules, r) })
+	}
+	return b
+}
+
+// CrawlDelay sets the Crawl-delay of the current group.
+func (b *Builder) CrawlDelay(d time.Duration) *Builder {
+	if !b.inGroup("Crawl-delay") {
+		return b
+	}
+	if d < 0 {
+		b.errorf("Crawl-delay %v is negative", d)
+		return b
+	}
+	parseGroupMap(b.groups, b.agents, func(g *Group) { g.CrawlDelay = d })
+	return b
+}
+
+// RequestRate sets the Request-rate of the current group to requests per
+// period.
+func (b *Builder) RequestRate(requests int, period time.Duration) *Builder {
+	if !b.inGroup("Request-rate") {
+		return b
+	}
+	if requests <= 0 || period <= 0 {
+		b.errorf("Request-rate %d/%v is not positive", requests, period)
+		return b
+	}
+	rate := RequestRate{Requests: requests, Period: period}
+	parseGroupMap(b.groups, b.agents, func(g *Group) { g.RequestRate = rate })
+	return b
+}
+
+// VisitTime adds a Visit-time window to the current group, start and end
+// are UTC times of day.
+func (b *Builder) VisitTime(start, end time.Duration) *Builder {
+	if !b.inGroup("Visit-time") {
+		return b
+	}
+	if start < 0 || start >= day || end < 0 || end > day || start == end ||
+		start%time.Minute != 0 || end%time.Minute != 0 {
+		b.errorf("Visit-time %v-%v is not a window of whole minutes in a day", start, end)
+		return b
+	}
+	w := TimeWindow{Start: start, End: end}
+	parseGroupMap(b.groups, b.agents, func(g *Group) { g.VisitTimes = append(g.VisitTimes, w) })
+	return b
+}
+
+// Usage adds a ContentSignal or ContentUsage line to the current group, as in
+// Usage(ContentSignal, "search=yes, ai-train=no").
+func (b *Builder) Usage(directive, value string) *Builder {
+	if !b.inGroup(directive) {
+		return b
+	}
+	if directive != ContentSignal && directive != ContentUsage {
+		b.errorf("%q is not a usage preference directive", directive)
+		return b
+	}
+	if strings.ContainsAny(value, "#\r\n") {
+		b.errorf("%s value %q cannot contain '#' or line breaks", directive, value)
+		return b
+	}
+	u, err := parseUsage(directive, value, func(msg string) { b.errorf("%s", msg) })
+	if err != nil {
+		b.errorf("%s value %q: %v", directive, value, err)
+		return b
+	}
+	parseGroupMap(b.groups, b.agents, func(g *Group) { g.usageRules = append(g.usageRules, u) })
+	return b
+}
+
+// Extension adds a line of a group-scoped extension directive to the current
+// group. Its Parsed value is the value itself, as for extensions without
+// Parse.
+func (b *Builder) Extension(name, value string) *Builder {
+	if !b.inGroup(name) {
+		return b
+	}
+	key := strings.ToLower(name)
+	if key == "" || strings.ContainsAny(key, ":#\r\n") || strings.IndexFunc(key, isSpace) >= 0 || isKnownDirective(key) {
+		b.errorf("%q is not an extension directive name", name)
+		return b
+	}
+	if strings.ContainsAny(value, "#\r\n") {
+		b.errorf("%s value %q cannot contain '#' or line breaks", name, value)
+		return b
+	}
+	d := Directive{Name: name, Key: name, Value: value, Parsed: value}
+	parseGroupMap(b.groups, b.agents, func(g *Group) { g.extensions = append(g.extensions, d) })
+	return b
+}
+
+// CleanParam adds a Clean-param rule to the current group. The parameters
+// are removed from URLs whose path matches pattern, or from all URLs if
+// pattern is empty.
+func (b *Builder) CleanParam(pattern string, params ...string) *Builder {
+	if !b.inGroup("Clean-param") {
+		return b
+	}
+	if len(params) == 0 {
+		b.errorf("Clean-param without parameters")
+		return b
+	}
+	for _, p := range params {
+		if p == "" || strings.IndexFunc(p, isSpace) >= 0 || strings.ContainsAny(p, "&#\r\n") {
+			b.errorf("Clean-param parameter %q is not a parameter name", p)
+			return b
+		}
+	}
+	li, err := b.pathVal("Clean-param", pattern)
+	if err != nil {
+		return b
+	}
+	r := &cleanParamRule{params: append([]string(nil), params...), path: li.vs, pattern: li.vr, raw: li.raw}
+	parseGroupMap(b.groups, b.agents, func(g *Group) { g.cleanParamRules = append(g.cleanParamRules, r) })
+	return b
+}
+
+// Sitemap adds absolute sitemap URLs.
+func (b *Builder) Sitemap(urls ...string) *Builder {
+	for _, s := range urls {
+		if u, err := url.Parse(s); err != nil || !u.IsAbs() || u.Host == "" || strings.ContainsAny(s, "# \t\r\n") {
+			b.errorf("Sitemap %q is not an absolute URL", s)
+			continue
+		}
+		b.sitemaps = append(b.sitemaps, s)
+	}
+	return b
+}
+
+// Host sets the main mirror of the site.
+func (b *Builder) Host(host string) *Builder {
+	if host == "" || strings.IndexFunc(host, isSpace) >= 0 || strings.ContainsAny(host, "#\r\n") {
+		b.errorf("Host %q is not a host name", host)
+		return b
+	}
+	b.host = host
+	return b
+}
+
+// Build returns the RobotsData built so far, or a *ParseError listing every
+// problem found while building. The builder can be used further, it does not
+// share groups with the returned RobotsData.
+func (b *Builder) Build() (*RobotsData, error) {
+	if len(b.errs) > 0 {
+		return nil, newParseError(b.errs, nil)
+	}
+	r := &RobotsData{
+		groups:   make(map[string]*Group, len(b.groups)),
+		Host:     b.host,
+		Sitemaps: append([]string(nil), b.sitemaps...),
+	}
+	for a, g := range b.groups {
+		c := *g
+		c.rules = append([]*rule(nil), g.rules...)
+		c.cleanParamRules = append([]*cleanParamRule(nil), g.cleanParamRules...)
+		c.VisitTimes = append([]TimeWindow(nil), g.VisitTimes...)
+		c.usageRules = make([]*usageRule, len(g.usageRules))
+		for i, u := range g.usageRules {
+			uc := *u
+			uc.prefs = make(map[string]Preference, len(u.prefs))
+			for k, v := range u.prefs {
+				uc.prefs[k] = v
+			}
+			c.usageRules[i] = &uc
+		}
+		c.extensions = append([]Directive(nil), g.extensions...)
+		r.groups[a] = &c
+	}
+	return r, nil
+}
+
+// Text returns the built policy as robots.txt text, see RobotsData.WriteTo.
+func (b *Builder) Text() (string, error) {
+	r, err := b.Build()
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// inGroup reports whether a group was started, members have nothing to
+// belong to otherwise.
+func (b *Builder) inGroup(key string) bool {
+	if len(b.agents) == 0 {
+		b.errorf("%s before Group", key)
+		return false
+	}
+	return true
+}
+
+// pathVal parses a path pattern the same way the parser does. Patterns that
+// could not be written as robots.txt text are rejected too.
+func (b *Builder) pathVal(key, pattern string) (*lineInfo, error) {
+	if strings.ContainsAny(pattern, "#\r\n") {
+		return nil, b.errorf("%s pattern %q cannot contain '#' or line breaks", key, pattern)
+	}
+	li, err := parsePathVal(lUnknown, key, pattern)
+	if err != nil {
+		return nil, b.errorf("%s pattern %q: %v", key, pattern, err)
+	}
+	return li, nil
+}
+
+// errorf records an error of the policy being built and returns it.
+func (b *Builder) errorf(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	b.errs = append(b.errs, err)
+	return err
+}
@@ -0,0 +1,164 @@
+package robotstxt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+	b := NewBuilder().
+		Group("Googlebot", "Bingbot").Disallow("/private", "").Allow("/private/open*").Disallow("/*.pdf$").
+		Group("*").Disallow("tmp").CrawlDelay(1500*time.Millisecond).CleanParam("/forum/*.php", "ref", "utm").CleanParam("", "sid").
+		Sitemap("https://example.com/sitemap.xml").
+		Host("example.com")
+
+	r, err := b.Build()
+	require.NoError(t, err)
+	assert.False(t, r.TestAgent("/private/x", "Googlebot"))
+	assert.True(t, r.TestAgent("/private/open/x", "bingbot"))
+	assert.False(t, r.TestAgent("/a.pdf", "Googlebot"))
+	assert.True(t, r.TestAgent("/tmp", "Googlebot"))
+	assert.False(t, r.TestAgent("/tmp/x", "other"))
+	assert.Equal(t, 1500*time.Millisecond, r.FindGroup("other").CrawlDelay)
+	u, err := r.FindGroup("other").CleanParamsString("/forum/a.php?ref=2&x=1&utm=3&sid=4")
+	require.NoError(t, err)
+	assert.Equal(t, "/forum/a.php?sid=4&x=1", u)
+	assert.Equal(t, []string{"https://example.com/sitemap.xml"}, r.Sitemaps)
+	assert.Equal(t, "example.com", r.Host)
+
+	text, err := b.Text()
+	require.NoError(t, err)
+	parsed, err := FromString(text)
+	require.NoError(t, err)
+	assert.Equal(t, text, parsed.String())
+	assert.Contains(t, text, "User-agent: *\nDisallow: /tmp\nCrawl-delay: 1.5\nClean-param: ref&utm /forum/*.php\nClean-param: sid\n")
+
+	// Building more does not change what was built.
+	b.Group("Googlebot").Disallow("/")
+	assert.True(t, r.TestAgent("/", "Googlebot"))
+	r2, err := b.Build()
+	require.NoError(t, err)
+	assert.False(t, r2.TestAgent("/", "Googlebot"))
+	assert.True(t, r2.TestAgent("/", "Bingbot"))
+}
+
+func TestBuilderEmpty(t *testing.T) {
+	t.Parallel()
+	r, err := NewBuilder().Build()
+	require.NoError(t, err)
+	assert.True(t, r.TestAgent("/", "bot"))
+	assert.Equal(t, "", r.String())
+
+	text, err := NewBuilder().Group("a", "b").Text()
+	require.NoError(t, err)
+	assert.Equal(t, "User-agent: a\nDisallow:\n\nUser-agent: b\nDisallow:\n", text)
+}
+
+func TestBuilderErrors(t *testing.T) {
+	t.Parallel()
+	r, err := NewBuilder().
+		Disallow("/early").
+		Group().
+		Group("good bot", "").
+		Group("bot").
+		Disallow("/a#b").
+		CrawlDelay(-time.Second).
+		CleanParam("/", "a&b").
+		CleanParam("/").
+		Sitemap("/sitemap.xml").
+		Host("example.com\nDisallow: /").
+		Build()
+	require.Nil(t, r)
+	require.IsType(t, &ParseError{}, err)
+	msgs := make([]string, 0)
+	for _, e := range err.(*ParseError).Errs {
+		msgs = append(msgs, e.Error())
+	}
+	assert.Equal(t, []string{
+		`Disallow before Group`,
+		`Group without agents`,
+		`User-agent "good bot" is not a product token`,
+		`User-agent "" is not a product token`,
+		`Disallow pattern "/a#b" cannot contain '#' or line breaks`,
+		`Crawl-delay -1s is negative`,
+		`Clean-param parameter "a&b" is not a parameter name`,
+		`Clean-param without parameters`,
+		`Sitemap "/sitemap.xml" is not an absolute URL`,
+		`Host "example.com\nDisallow: /" is not a host name`,
+	}, msgs)
+}
+
+func TestBuilderExtension(t *testing.T) {
+	t.Parallel()
+	r, err := NewBuilder().Group("*").
+		Disallow("/private").
+		Extension("Noindex", "/drafts").
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, []Directive{{Name: "Noindex", Key: "Noindex", Value: "/drafts", Parsed: "/drafts"}}, r.FindGroup("bot").Extension("Noindex"))
+	assert.Equal(t, "User-agent: *\nDisallow: /private\nNoindex: /drafts\n", r.String())
+
+	_, err = NewBuilder().Group("*").
+		Extension("Disallow", "/").
+		Extension("X Thing", "on").
+		Extension("X-Thing", "a # b").
+		Build()
+	require.IsType(t, &ParseError{}, err)
+	assert.Len(t, err.(*ParseError).Errs, 3)
+}
+
+func TestBuilderUsage(t *testing.T) {
+	t.Parallel()
+	r, err := NewBuilder().Group("*").
+		Disallow("/private").
+		Usage(ContentSignal, "search=yes, ai-train=no").
+		Usage(ContentUsage, "/blog/ train-ai=y").
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, PreferenceDisallow, r.UsagePreference("/", "bot", "ai-train"))
+	assert.Equal(t, PreferenceAllow, r.UsagePreference("/blog/a", "bot", "train-ai"))
+	assert.Equal(t, "User-agent: *\nDisallow: /private\nContent-Signal: search=yes, ai-train=no\nContent-Usage: /blog/ train-ai=y\n", r.String())
+
+	_, err = NewBuilder().Group("*").
+		Usage("Content-Whatever", "a=yes").
+		Usage(ContentSignal, "ai-train=maybe").
+		Usage(ContentSignal, "a=yes # b=no").
+		Build()
+	require.IsType(t, &ParseError{}, err)
+	assert.Len(t, err.(*ParseError).Errs, 3)
+}
+
+func TestBuilderBuildCopies(t *testing.T) {
+	t.Parallel()
+	b := NewBuilder().Group("*").
+		Usage(ContentSignal, "search=yes").
+		Extension("Noindex", "/drafts")
+	r, err := b.Build()
+	require.NoError(t, err)
+
+	// Building more does not change what was built.
+	before := r.String()
+	b.Usage(ContentSignal, "ai-train=yes").Extension("Noindex", "/tmp")
+	b.groups["*"].usageRules[0].prefs["search"] = PreferenceDisallow
+	b.groups["*"].extensions[0].Value = "/changed"
+	assert.Equal(t, before, r.String())
+	assert.Equal(t, PreferenceAllow, r.UsagePreference("/", "bot", "search"))
+	assert.Len(t, r.FindGroup("bot").Extension("Noindex"), 1)
+	r2, err := b.Build()
+	require.NoError(t, err)
+	assert.Len(t, r2.FindGroup("bot").Extension("Noindex"), 2)
+	assert.Len(t, r2.FindGroup("bot").UsageRules(), 2)
+}
+
+func TestBuilderCleanParamCopies(t *testing.T) {
+	t.Parallel()
+	params := []string{"ref", "utm"}
+	r, err := NewBuilder().Group("*").CleanParam("", params...).Build()
+	require.NoError(t, err)
+	params[0] = "sid"
+	assert.Equal(t, []string{"ref", "utm"}, r.FindGroup("bot").CleanParamRules()[0].Params)
+}
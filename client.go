@@ -0,0 +1,358 @@
+package robotstxt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultMaxAge is the longest time a Client uses a robots.txt file without
+// fetching it again. From RFC 9309 section 2.4:
+// Crawlers SHOULD NOT use the cached version for more than 24 hours, unless
+// the robots.txt file is unreachable.
+const DefaultMaxAge = 24 * time.Hour
+
+// DefaultRetryInterval is how long a Client keeps an unreachable result
+// before it fetches the file again.
+const DefaultRetryInterval = 5 * time.Minute
+
+// DefaultMaxStale is how long a Client uses the last good copy of a file
+// that became unreachable. From RFC 9309 section 2.3.1.4:
+// If the robots.txt file is unreachable for a reasonably long period of time
+// (for example, 30 days), crawlers MAY assume that the robots.txt file is
+// unavailable as defined in Section 2.3.1.3 or continue to use a cached copy.
+const DefaultMaxStale = 30 * 24 * time.Hour
+
+// StalePolicy says what a Client does while a file is unreachable, that is
+// while fetches give results with RobotsData.Unreachable set.
+type StalePolicy struct {
+	// MaxStale is how long after the first failure the last good copy is
+	// used, DefaultMaxStale if zero. Negative turns stale copies off: the
+	// origin is unreachable for DefaultMaxStale, then Fallback applies.
+	MaxStale time.Duration
+
+	// Fallback is the action once MaxStale has passed: StatusAllowAll,
+	// StatusDisallowAll or StatusUnreachable. The zero value means
+	// StatusUnreachable, so everything stays disallowed. Before MaxStale
+	// has passed, an origin without a good copy is unreachable.
+	Fallback StatusAction
+}
+
+// Client fetches robots.txt files and caches them per origin. Files are kept
+// as long as their Cache-Control max-age says, up to MaxAge, and revalidated
+// with If-None-Match and If-Modified-Since after that. Concurrent lookups of
+// the same origin share one fetch.
+//
+// The zero value is not usable, create one with NewClient. A Client is safe
+// for concurrent use.
+type Client struct {
+	Fetcher *Fetcher
+
+	// MaxAge caps the time a file is used without fetching it again,
+	// DefaultMaxAge if zero.
+	MaxAge time.Duration
+
+	// RetryInterval caps the time a result is used while the file is
+	// unreachable, DefaultRetryInterval if zero.
+	RetryInterval time.Duration
+
+	// Stale says how unreachable files are handled.
+	Stale StalePolicy
+
+	// Store keeps fetched files across restarts, if not nil. Files are
+	// loaded from it before they are fetched the first time, and are
+	// parsed again with the policy and options of the Fetcher. Errors of
+	// the Store are ignored, it only saves fetches.
+	Store Store
+
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*cacheEntry // By origin
+	pruneAt int                    // Size of entries that triggers pruning
+}
+
+type cacheEntry struct {
+	robots       *RobotsData // Result given out until expires
+	expires      time.Time
+	good         *RobotsData // Last result that was not unreachable
+	body         []byte      // Raw body of good, kept if there is a Store
+	failingSince time.Time   // Time of the first unreachable result in a row
+	call         *fetchCall  // Fetch in progress, or nil
+}
+
+// fetchCall is a fetch shared by concurrent lookups of an origin.
+type fetchCall struct {
+	done   chan struct{}
+	robots *RobotsData
+	err    error
+}
+
+// NewClient returns a Client that fetches files with f, or with a zero
+// Fetcher if f is nil.
+func NewClient(f *Fetcher) *Client {
+	if f == nil {
+		f = &Fetcher{}
+	}
+	return &Client{Fetcher: f, now: time.Now, entries: make(map[string]*cacheEntry), pruneAt: minPrune}
+}
+
+// Get returns the robots.txt file that applies to pageURL, from the cache if
+// it is fresh. When several goroutines wait for the same fetch, the context
+// of the one that started it applies to the fetch.
+func (c *Client) Get(ctx context.Context, pageURL string) (*RobotsData, error) {
+	u, err := RobotsURL(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	key := origin(u)
+
+	c.mu.Lock()
+	e := c.entries[key]
+	if e == nil {
+		if len(c.entries) >= c.pruneAt {
+			c.prune(c.now())
+		}
+		e = &cacheEntry{}
+		c.entries[key] = e
+	}
+	if e.robots != nil && c.now().Before(e.expires) {
+		r := e.robots
+		c.mu.Unlock()
+		return r, nil
+	}
+	call := e.call
+	if call == nil {
+		call = &fetchCall{done: make(chan struct{})}
+		e.call = call
+		good, body := e.good, e.body
+		c.mu.Unlock()
+		c.refresh(ctx, e, u, good, body, call)
+	} else {
+		c.mu.Unlock()
+	}
+
+	select {
+	case <-call.done:
+		return call.robots, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TestURL reports whether agent may crawl pageURL, see RobotsData.TestURL.
+func (c *Client) TestURL(ctx context.Context, pageURL, agent string) (bool, error) {
+	r, err := c.Get(ctx, pageURL)
+	if err != nil {
+		return false, err
+	}
+	return r.TestURLString(pageURL, agent)
+}
+
+// FailingSince returns the time since when the robots.txt file for pageURL
+// has been unreachable, or false if it is not known to be.
+func (c *Client) FailingSince(pageURL string) (time.Time, bool) {
+	u, err := RobotsURL(pageURL)
+	if err != nil {
+		return time.Time{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.entries[origin(u)]; e != nil && !e.failingSince.IsZero() {
+		return e.failingSince, true
+	}
+	return time.Time{}, false
+}
+
+// refresh fetches the file again, conditionally if the last good copy can
+// be revalidated, and stores the result in e. Errors are not cached. If e
+// has no good copy yet, it is looked up in the Store first.
+func (c *Client) refresh(ctx context.Context, e *cacheEntry, u *url.URL, good *RobotsData, body []byte, call *fetchCall) {
+	if good == nil && c.Store != nil {
+		if good, body = c.load(ctx, origin(u)); good != nil {
+			c.mu.Lock()
+			e.good, e.body = good, body
+			if expires := good.FetchedAt.Add(c.ttl(e, good)); c.now().Before(expires) {
+				e.robots, e.expires = good, expires
+				c.done(e, call, good, nil)
+				c.mu.Unlock()
+				return
+			}
+			c.mu.Unlock()
+		}
+	}
+
+	var header http.Header
+	if good != nil && good.StatusCode >= 200 && good.StatusCode < 300 {
+		header = make(http.Header)
+		if good.ETag != "" {
+			header.Set("If-None-Match", good.ETag)
+		}
+		if good.LastModified != "" {
+			header.Set("If-Modified-Since", good.LastModified)
+		}
+	}
+	var raw io.Writer
+	buf := new(bytes.Buffer)
+	if c.Store != nil {
+		raw = buf
+	}
+	r, res, err := c.Fetcher.fetch(ctx, u, header, raw)
+	now := c.now()
+
+	var rec *Record
+	c.mu.Lock()
+	if err == nil {
+		if r == nil {
+			r = good.revalidated(res)
+			buf = bytes.NewBuffer(body)
+		}
+		r.FetchedAt = now
+		if c.Store != nil && !r.Unreachable() {
+			rec = newRecord(origin(u), r, buf.Bytes())
+			e.body = rec.Body
+		}
+		r = c.applyStale(e, r, now)
+		e.robots, e.expires = r, now.Add(c.ttl(e, r))
+	}
+	c.done(e, call, r, err)
+	c.mu.Unlock()
+
+	if rec != nil {
+		_ = c.Store.Put(ctx, rec)
+	}
+}
+
+// done finishes the fetch call of e with its result. c.mu must be held.
+func (c *Client) done(e *cacheEntry, call *fetchCall, r *RobotsData, err error) {
+	e.call = nil
+	call.robots, call.err = r, err
+	close(call.done)
+}
+
+// load returns the stored file of origin and its raw body, or nil.
+func (c *Client) load(ctx context.Context, origin string) (*RobotsData, []byte) {
+	rec, err := c.Store.Get(ctx, origin)
+	if err != nil {
+		return nil, nil
+	}
+	r, err := rec.Parse(c.Fetcher.policy(), c.Fetcher.Options)
+	if err != nil || r.Unreachable() {
+		return nil, nil
+	}
+	return r, rec.Body
+}
+
+func newRecord(origin string, r *RobotsData, body []byte) *Record {
+	return &Record{
+		Origin:       origin,
+		StatusCode:   r.StatusCode,
+		Body:         body,
+		FetchedAt:    r.FetchedAt,
+		ETag:         r.ETag,
+		LastModified: r.LastModified,
+		CacheControl: r.CacheControl,
+	}
+}
+
+// applyStale tracks failures of the origin of e and returns what to use
+// instead of the fetched result r, following c.Stale.
+func (c *Client) applyStale(e *cacheEntry, r *RobotsData, now time.Time) *RobotsData {
+	if !r.Unreachable() {
+		e.good, e.failingSince = r, time.Time{}
+		return r
+	}
+	if e.failingSince.IsZero() {
+		e.failingSince = now
+	}
+
+	if now.Sub(e.failingSince) >= c.maxStale() {
+		fallback := fromAction(c.Stale.Fallback)
+		if fallback == nil || fallback.Unreachable() {
+			return r
+		}
+		fallback.Origin, fallback.FetchedAt, fallback.StatusCode = r.Origin, r.FetchedAt, r.StatusCode
+		return fallback
+	}
+	if e.good != nil && c.Stale.MaxStale >= 0 {
+		stale := *e.good
+		stale.stale = true
+		return &stale
+	}
+	return r
+}
+
+// maxStale returns how long after the first failure Stale.Fallback applies.
+func (c *Client) maxStale() time.Duration {
+	if c.Stale.MaxStale <= 0 {
+		return DefaultMaxStale
+	}
+	return c.Stale.MaxStale
+}
+
+// prune forgets origins whose results expired and are not being fetched.
+// Unreachable origins are kept to track their failures, unless nobody asked
+// for them for MaxStale. Like Politeness.prune, it runs when the number of
+// origins doubles.
+func (c *Client) prune(now time.Time) {
+	maxStale := c.maxStale()
+	for key, e := range c.entries {
+		if e.call != nil || now.Before(e.expires) {
+			continue
+		}
+		if e.failingSince.IsZero() || now.Sub(e.expires) >= maxStale {
+			delete(c.entries, key)
+		}
+	}
+	c.pruneAt = 2 * len(c.entries)
+	if c.pruneAt < minPrune {
+		c.pruneAt = minPrune
+	}
+}
+
+// ttl returns how long r, the result for e, may be used.
+func (c *Client) ttl(e *cacheEntry, r *RobotsData) time.Duration {
+	limit := c.MaxAge
+	if limit <= 0 {
+		limit = DefaultMaxAge
+	}
+	if !e.failingSince.IsZero() {
+		retry := c.RetryInterval
+		if retry <= 0 {
+			retry = DefaultRetryInterval
+		}
+		if retry < limit {
+			limit = retry
+		}
+	}
+	if age, ok := r.MaxAge(); ok && age < limit {
+		return age
+	}
+	return limit
+}
+
+// revalidated returns a copy of r updated with the headers of a 304 "Not
+// Modified" response. Copies share the parsed rules, which never change.
+func (r *RobotsData) revalidated(res *http.Response) *RobotsData {
+	c := *r
+	c.stale = false
+	if v := res.Header.Get("ETag"); v != "" {
+		c.ETag = v
+	}
+	if v := res.Header.Get("Last-Modified"); v != "" {
+		c.LastModified = v
+	}
+	if v := res.Header.Get("Cache-Control"); v != "" {
+		c.CacheControl = v
+	}
+	return &c
+}
+
+// Stale reports whether r is the last good copy of a file that is
+// unreachable now, given out by a Client, see StalePolicy.
+func (r *RobotsData) Stale() bool {
+	return r.stale
+}
@@ -0,0 +1,422 @@
+package robotstxt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNow is a settable clock for tests.
+type fakeNow struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (f *fakeNow) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.t
+}
+
+func (f *fakeNow) Add(d time.Duration) {
+	f.mu.Lock()
+	f.t = f.t.Add(d)
+	f.mu.Unlock()
+}
+
+func newTestClient(t *testing.T, h http.HandlerFunc) (*Client, *httptest.Server, *fakeNow) {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	clock := &fakeNow{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewClient(nil)
+	c.now = clock.Now
+	return c, ts, clock
+}
+
+func TestClientRevalidation(t *testing.T) {
+	t.Parallel()
+	var fetches, notModified int32
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Mon, 01 Jan 2024 00:00:00 GMT" {
+			atomic.AddInt32(&notModified, 1)
+			w.Header().Set("Cache-Control", "max-age=120")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		io.WriteString(w, robotsFetched)
+	})
+	ctx := context.Background()
+
+	r1, err := c.Get(ctx, ts.URL+"/a")
+	require.NoError(t, err)
+	expectAllAgents(t, r1, false, "/private")
+	r2, err := c.Get(ctx, ts.URL+"/b?c=d")
+	require.NoError(t, err)
+	assert.True(t, r1 == r2)
+	assert.EqualValues(t, 1, fetches)
+
+	clock.Add(61 * time.Second)
+	r3, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, fetches)
+	assert.EqualValues(t, 1, notModified)
+	assert.True(t, r1 != r3)
+	expectAllAgents(t, r3, false, "/private")
+	assert.Equal(t, clock.Now(), r3.FetchedAt)
+	assert.Equal(t, "max-age=120", r3.CacheControl)
+	assert.Equal(t, "max-age=60", r1.CacheControl)
+
+	// The 304 refreshed the entry with its own max-age.
+	clock.Add(119 * time.Second)
+	_, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, fetches)
+	clock.Add(2 * time.Second)
+	_, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, fetches)
+
+	allow, err := c.TestURL(ctx, ts.URL+"/private/x", "bot")
+	require.NoError(t, err)
+	assert.False(t, allow)
+}
+
+func TestClientMaxAge(t *testing.T) {
+	t.Parallel()
+	var fetches int32
+	status := int32(200)
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		w.Header().Set("Cache-Control", "max-age=31536000")
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		io.WriteString(w, robotsFetched)
+	})
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	clock.Add(DefaultMaxAge - time.Second)
+	_, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, fetches)
+	clock.Add(time.Second)
+
+	// Results are kept for a short while only when the file is unreachable.
+	atomic.StoreInt32(&status, 503)
+	r, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.True(t, r.Stale())
+	assert.EqualValues(t, 2, fetches)
+	clock.Add(DefaultRetryInterval)
+	atomic.StoreInt32(&status, 200)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.Unreachable())
+	assert.EqualValues(t, 3, fetches)
+
+	c.MaxAge = time.Minute
+	clock.Add(DefaultMaxAge)
+	_, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	clock.Add(time.Minute)
+	_, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, fetches)
+}
+
+func TestClientStale(t *testing.T) {
+	t.Parallel()
+	status := int32(200)
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		code := int(atomic.LoadInt32(&status))
+		if code == 0 {
+			panic(http.ErrAbortHandler) // network error
+		}
+		w.WriteHeader(code)
+		io.WriteString(w, robotsFetched)
+	})
+	c.Stale.Fallback = StatusAllowAll
+	ctx := context.Background()
+	start := clock.Now()
+
+	_, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	_, failing := c.FailingSince(ts.URL)
+	assert.False(t, failing)
+
+	// The last good copy is used while the file is unreachable.
+	clock.Add(DefaultMaxAge)
+	atomic.StoreInt32(&status, 503)
+	r, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.True(t, r.Stale())
+	assert.False(t, r.Unreachable())
+	assert.False(t, r.TestAgent("/private", "bot"))
+	assert.True(t, r.TestAgent("/public", "bot"))
+	since, failing := c.FailingSince(ts.URL)
+	assert.True(t, failing)
+	assert.Equal(t, start.Add(DefaultMaxAge), since)
+
+	clock.Add(DefaultMaxStale - DefaultRetryInterval)
+	atomic.StoreInt32(&status, 0)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.True(t, r.Stale())
+
+	// Then the fallback applies, until the file is back.
+	clock.Add(DefaultRetryInterval)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.Stale())
+	assert.True(t, r.TestAgent("/private", "bot"))
+	since2, _ := c.FailingSince(ts.URL)
+	assert.Equal(t, since, since2)
+
+	clock.Add(DefaultRetryInterval)
+	atomic.StoreInt32(&status, 200)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.Stale())
+	assert.False(t, r.TestAgent("/private", "bot"))
+	_, failing = c.FailingSince(ts.URL)
+	assert.False(t, failing)
+}
+
+func TestClientStaleWithoutCopy(t *testing.T) {
+	t.Parallel()
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx := context.Background()
+
+	// Without a good copy, an unreachable file disallows everything.
+	r, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.True(t, r.Unreachable())
+	assert.False(t, r.Stale())
+
+	// By default it still does after MaxStale.
+	clock.Add(DefaultMaxStale)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.True(t, r.Unreachable())
+
+	c.Stale.Fallback = StatusDisallowAll
+	clock.Add(DefaultRetryInterval)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.Unreachable())
+	assert.False(t, r.TestAgent("/", "bot"))
+	assert.Equal(t, http.StatusServiceUnavailable, r.StatusCode)
+}
+
+func TestClientStaleOff(t *testing.T) {
+	t.Parallel()
+	status := int32(200)
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		io.WriteString(w, robotsFetched)
+	})
+	c.Stale = StalePolicy{MaxStale: -1, Fallback: StatusAllowAll}
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+
+	// The good copy is not used, and the fallback waits for DefaultMaxStale.
+	clock.Add(DefaultMaxAge)
+	atomic.StoreInt32(&status, 503)
+	r, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.Stale())
+	assert.True(t, r.Unreachable())
+	assert.False(t, r.TestAgent("/public", "bot"))
+
+	clock.Add(DefaultMaxStale)
+	r, err = c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.Unreachable())
+	assert.True(t, r.TestAgent("/private", "bot"))
+}
+
+func TestClientStore(t *testing.T) {
+	t.Parallel()
+	var fetches, notModified int32
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, robotsFetched)
+	})
+	store := &MemoryStore{}
+	c.Store = store
+	ctx := context.Background()
+
+	_, err := c.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	rec, err := store.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.Equal(t, robotsFetched, string(rec.Body))
+	assert.Equal(t, clock.Now(), rec.FetchedAt)
+
+	// A new client uses the stored file while it is fresh.
+	c2 := NewClient(nil)
+	c2.now, c2.Store = clock.Now, store
+	r, err := c2.Get(ctx, ts.URL+"/page")
+	require.NoError(t, err)
+	assert.False(t, r.TestAgent("/private", "bot"))
+	assert.EqualValues(t, 1, fetches)
+
+	// Then revalidates it, and stores it again.
+	clock.Add(time.Minute)
+	r, err = c2.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.TestAgent("/private", "bot"))
+	assert.EqualValues(t, 2, fetches)
+	assert.EqualValues(t, 1, notModified)
+	rec, err = store.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.Equal(t, robotsFetched, string(rec.Body))
+	assert.Equal(t, clock.Now(), rec.FetchedAt)
+
+	// Expired copies are revalidated right away.
+	clock.Add(time.Minute)
+	c3 := NewClient(nil)
+	c3.now, c3.Store = clock.Now, store
+	r, err = c3.Get(ctx, ts.URL)
+	require.NoError(t, err)
+	assert.False(t, r.TestAgent("/private", "bot"))
+	assert.EqualValues(t, 2, notModified)
+}
+
+func TestClientDeduplicates(t *testing.T) {
+	t.Parallel()
+	var fetches int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	c, ts, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			close(entered)
+		}
+		<-release
+		io.WriteString(w, robotsFetched)
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*RobotsData, 50)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := c.Get(context.Background(), ts.URL+"/page")
+			assert.NoError(t, err)
+			results[i] = r
+		}(i)
+	}
+	<-entered
+	// A waiter may give up without affecting the fetch.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Get(ctx, ts.URL)
+	assert.True(t, errors.Is(err, context.Canceled))
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, fetches)
+	for _, r := range results {
+		assert.True(t, results[0] == r)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	t.Parallel()
+	c, ts, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "compress")
+		io.WriteString(w, robotsFetched)
+	})
+	_, err := c.Get(context.Background(), ts.URL)
+	assert.Error(t, err)
+	// Errors are not cached.
+	c.Fetcher.Decoders = map[string]Decoder{"compress": func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }}
+	r, err := c.Get(context.Background(), ts.URL)
+	require.NoError(t, err)
+	expectAllAgents(t, r, false, "/private")
+
+	_, err = c.Get(context.Background(), "/relative")
+	assert.Error(t, err)
+}
+
+func TestClientPrune(t *testing.T) {
+	t.Parallel()
+	c, ts, clock := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Host, "down.") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, robotsFetched)
+	})
+	// Every host is served by ts.
+	c.Fetcher.Client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, ts.Listener.Addr().String())
+		},
+	}}
+	ctx := context.Background()
+	get := func(host string) {
+		_, err := c.Get(ctx, "http://"+host+"/")
+		require.NoError(t, err)
+	}
+	cached := func() []string {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		keys := make([]string, 0, len(c.entries))
+		for k := range c.entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	get("a.example")
+	get("b.example")
+	get("down.example")
+	clock.Add(30 * time.Second)
+	get("c.example")
+	assert.Len(t, cached(), 4)
+
+	// Expired origins go, fresh and failing ones stay.
+	clock.Add(31 * time.Second)
+	c.pruneAt = 4
+	get("d.example")
+	assert.Equal(t, []string{"http://c.example", "http://d.example", "http://down.example"}, cached())
+	assert.Equal(t, minPrune, c.pruneAt)
+
+	// Failing origins go once nobody asked for them for MaxStale.
+	c.Stale.MaxStale = time.Hour
+	clock.Add(DefaultRetryInterval + time.Hour)
+	c.pruneAt = 3
+	get("e.example")
+	assert.Equal(t, []string{"http://e.example"}, cached())
+}
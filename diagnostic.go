@@ -0,0 +1,92 @@
+package robotstxt
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// Severity tells how much a Diagnostic matters for the meaning of the file.
+type Severity int
+
+const (
+	// SeverityInfo marks lines that are valid but have no effect here.
+	SeverityInfo Severity = iota
+	// SeverityWarning marks lines that were used, but may not say what the
+	// author intended.
+	SeverityWarning
+	// SeverityError marks lines that could not be interpreted and were
+	// ignored or replaced with a default.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Stable codes of the diagnostics reported by the scanner and the parser.
+const (
+	CodeInvalidUTF8        = "invalid-utf8"
+	CodeMissingColon       = "missing-colon"
+	CodeHTML               = "html"
+	CodeWhitespaceInValue  = "whitespace-in-value"
+	CodeExtraFields        = "extra-fields"
+	CodeInvalidCrawlDelay  = "invalid-crawl-delay"
+	CodeInvalidRequestRate = "invalid-request-rate"
+	CodeInvalidVisitTime   = "invalid-visit-time"
+	CodeInvalidExtension   = "invalid-extension"
+	CodeInvalidUsage       = "invalid-usage"
+	CodeInvalidPattern     = "invalid-pattern"
+	CodeUnknownDirective   = "unknown-directive"
+)
+
+// Diagnostic describes a problem found on a line of a robots.txt file.
+// Line and Column are 1-based, Column counts characters.
+type Diagnostic struct {
+	Line     int
+	Column   int
+	Severity Severity
+	Code     string
+	Message  string
+}
+
+func newDiagnostic(pos token.Position, severity Severity, code, msg string) Diagnostic {
+	return Diagnostic{
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: severity,
+		Code:     code,
+		Message:  msg,
+	}
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", d.Line, d.Column, d.Severity, d.Message, d.Code)
+}
+
+// Parse parses robots.txt content like FromBytes and also returns the
+// diagnostics found along the way, ordered by position. Diagnostics do not
+// make parsing fail, but they are attached to the ParseError if it does.
+func Parse(body []byte) (*RobotsData, []Diagnostic, error) {
+	return parse("bytes", body)
+}
+
+// sortDiagnostics orders diagnostics by position. Scanner diagnostics are
+// collected before parser ones, so the slice is only partially ordered.
+func sortDiagnostics(diags []Diagnostic) []Diagnostic {
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+		return diags[i].Column < diags[j].Column
+	})
+	return diags
+}
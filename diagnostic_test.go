@@ -0,0 +1,76 @@
+package robotstxt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+	const robotsWithProblems = "\xef\xbb\xbfUser-agent: *\r\n" +
+		"Crawl-delay: soon\r\n" +
+		"  Crawl-delay: -1\r\n" +
+		"Noindex: /private\r\n" +
+		"Disallow /tmp\r\n" +
+		"<p>Not robots.txt</p>\r\n" +
+		"Allow: /caf\xe9\r\n"
+
+	r, diags, err := Parse([]byte(robotsWithProblems))
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, []Diagnostic{
+		{2, 1, SeverityError, CodeInvalidCrawlDelay, `Crawl-delay value "soon" is not a number`},
+		{3, 3, SeverityError, CodeInvalidCrawlDelay, `Crawl-delay value "-1" is out of range`},
+		{4, 1, SeverityInfo, CodeUnknownDirective, `unknown directive "Noindex"`},
+		{5, 1, SeverityWarning, CodeMissingColon, `missing colon after "Disallow"`},
+		{6, 1, SeverityWarning, CodeHTML, "skipped HTML markup"},
+		{7, 12, SeverityWarning, CodeInvalidUTF8, "illegal UTF-8 encoding"},
+	}, diags)
+	assert.Equal(t, time.Duration(0), r.FindGroup("bot").CrawlDelay)
+}
+
+func TestDiagnosticPositions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name  string
+		input string
+		line  int
+		col   int
+	}{
+		{"lf", "User-agent: *\n\nFoo: bar", 3, 1},
+		{"crlf", "User-agent: *\r\n\r\nFoo: bar", 3, 1},
+		{"cr", "User-agent: *\r\rFoo: bar", 3, 1},
+		{"indent", "User-agent: *\n\t  Foo: bar", 2, 4},
+		{"unicode", "User-agent: *\n# ěščř\nFoo: bar", 3, 1},
+		{"bom", "\xef\xbb\xbfFoo: bar", 1, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, diags, err := Parse([]byte(c.input))
+			require.NoError(t, err)
+			require.Len(t, diags, 1)
+			assert.Equal(t, CodeUnknownDirective, diags[0].Code)
+			assert.Equal(t, c.line, diags[0].Line)
+			assert.Equal(t, c.col, diags[0].Column)
+		})
+	}
+}
+
+func TestDiagnosticsClean(t *testing.T) {
+	t.Parallel()
+	_, diags, err := Parse([]byte(robotsText001))
+	require.NoError(t, err)
+	assert.Empty(t, diags)
+}
+
+func TestDiagnosticError(t *testing.T) {
+	d := Diagnostic{Line: 3, Column: 1, Severity: SeverityWarning, Code: CodeMissingColon, Message: `missing colon after "Disallow"`}
+	assert.Equal(t, `3:1: warning: missing colon after "Disallow" (missing-colon)`, d.Error())
+
+	e := newParseError([]error{d}, []Diagnostic{d})
+	assert.Contains(t, e.Error(), d.Error())
+	assert.Equal(t, []Diagnostic{d}, e.Diagnostics)
+}
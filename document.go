@@ -0,0 +1,37 @@
+package robotstxt
+
+import (
+	"go/token"
+
+	"github.com/temoto/robotstxt/ast"
+)
+
+// FromAST builds RobotsData from a document model, giving the same result
+// as parsing the document's text with FromBytes.
+func FromAST(f *ast.File) (*RobotsData, error) {
+	r, _, err := parseAST("ast", f)
+	return r, err
+}
+
+// ASTExtensions returns the extensions of reg for ast.File.GroupsWith, so
+// that the groups of a document are formed as the parser forms them with
+// reg. A nil Registry knows no extensions.
+func (reg *Registry) ASTExtensions() ast.ExtensionFunc {
+	return func(name string) (ok, global bool) {
+		ext := reg.lookup(name)
+		return ext != nil, ext != nil && ext.Scope == ScopeGlobal
+	}
+}
+
+func parseAST(srcname string, f *ast.File) (r *RobotsData, diags []Diagnostic, err error) {
+	directives := f.Directives()
+	lines := make([]line, 0, len(directives))
+	for _, d := range directives {
+		lines = append(lines, line{
+			key:   d.Key,
+			value: d.Value,
+			pos:   token.Position{Filename: srcname, Offset: d.Pos.Offset, Line: d.Pos.Line, Column: d.Pos.Column},
+		})
+	}
+	return parseLines(srcname, lines, nil, nil)
+}
@@ -0,0 +1,98 @@
+package robotstxt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/temoto/robotstxt/ast"
+)
+
+func TestFromAST(t *testing.T) {
+	t.Parallel()
+	inputs := map[string]string{
+		"001":         robotsText001,
+		"google":      robotsGoogle,
+		"matching":    robotsCaseMatching,
+		"precedence":  robotsCasePrecedence,
+		"vanityfair":  robotsTextVanityfair,
+		"html":        robotsTextHTMLAndComments,
+		"justhtml":    robotsTextJustHTML,
+		"clean-param": "User-agent: *\nClean-param: ref /some_dir/get_book.pl\nDisallow /tmp\r\nCrawl-delay: 3",
+	}
+	for name, input := range inputs {
+		r, err := FromString(input)
+		require.NoError(t, err)
+		r2, err := FromAST(ast.Parse([]byte(input)))
+		require.NoError(t, err)
+		assert.Equal(t, r.String(), r2.String(), name)
+	}
+}
+
+func TestFromASTEdited(t *testing.T) {
+	t.Parallel()
+	f := ast.Parse([]byte("# Managed file\nUser-agent: *\nDisallow: /private # keep out\n"))
+	g := f.Groups()[0]
+	f.InsertAfter(g.Members[0], ast.NewDirective("Allow", "/private/open"))
+	g.Members[0].SetValue("/secret")
+
+	assert.Equal(t, "# Managed file\nUser-agent: *\nDisallow: /secret # keep out\nAllow: /private/open\n", string(f.Bytes()))
+	r, err := FromAST(f)
+	require.NoError(t, err)
+	expectAllAgents(t, r, false, "/secret/x")
+	expectAllAgents(t, r, true, "/private/open")
+}
+
+func TestASTGroupsMatchParser(t *testing.T) {
+	t.Parallel()
+	values := map[string]string{"crawl-delay": "1", "request-rate": "1/5", "visit-time": "0100-0200", "clean-param": "ref",
+		"content-signal": "search=yes", "content-usage": "train-ai=n"}
+	keys := append([]string(nil), knownDirectives...)
+	for alias := range directiveAliases {
+		keys = append(keys, alias)
+	}
+	for _, key := range keys {
+		canonical := key
+		if c, ok := directiveAliases[key]; ok {
+			canonical = c
+		}
+		if canonical == "user-agent" || canonical == "sitemap" || canonical == "host" {
+			continue
+		}
+		value := values[canonical]
+		if value == "" {
+			value = "/x"
+		}
+		// The parser and the ast agree on which lines end the list of agents.
+		input := "User-agent: a\n" + key + ": " + value + "\nUser-agent: b\nDisallow: /b\n"
+		r, err := FromString(input)
+		require.NoError(t, err)
+		groups := ast.Parse([]byte(input)).Groups()
+		assert.Equal(t, r.TestAgent("/b", "a"), len(groups) == 2, key)
+	}
+}
+
+func TestASTExtensions(t *testing.T) {
+	t.Parallel()
+	reg, err := NewRegistry(
+		Extension{Name: "Noindex", Scope: ScopeGroup},
+		Extension{Name: "X-Contact", Scope: ScopeGlobal},
+	)
+	require.NoError(t, err)
+	const input = "User-agent: a\nX-Contact: me@example.com\nNoindex: /x\nUser-agent: b\nDisallow: /b\n"
+	r, err := FromReader(strings.NewReader(input), ParseOptions{Extensions: reg})
+	require.NoError(t, err)
+	assert.True(t, r.TestAgent("/b", "a"))
+	assert.Len(t, r.FindGroup("a").Extension("Noindex"), 1)
+
+	groups := ast.Parse([]byte(input)).GroupsWith(reg.ASTExtensions())
+	require.Len(t, groups, 2)
+	assert.Equal(t, "a", groups[0].Agents[0].Value)
+	require.Len(t, groups[0].Members, 1)
+	assert.Equal(t, "Noindex", groups[0].Members[0].Key)
+	assert.Equal(t, "b", groups[1].Agents[0].Value)
+
+	// Without the registry both are unknown and b shares the group of a.
+	assert.Len(t, ast.Parse([]byte(input)).GroupsWith((*Registry)(nil).ASTExtensions()), 1)
+}
@@ -0,0 +1,146 @@
+package robotstxt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selection tells how the group for an agent was chosen.
+type Selection int
+
+const (
+	// SelectNone means no group applies to the agent, everything is allowed.
+	SelectNone Selection = iota
+	// SelectAgent means the group of a user-agent matching the agent was
+	// chosen, see AgentMatchMode.
+	SelectAgent
+	// SelectDefault means no user-agent matched and the "*" group was chosen.
+	SelectDefault
+)
+
+func (s Selection) String() string {
+	switch s {
+	case SelectNone:
+		return "none"
+	case SelectAgent:
+		return "agent"
+	case SelectDefault:
+		return "default"
+	}
+	return fmt.Sprintf("Selection(%d)", int(s))
+}
+
+// Match is a rule that matched the path, with the length it was ranked by.
+type Match struct {
+	Rule
+	// Length is the match length compared between rules, it depends on the
+	// MatchMode of the group.
+	Length int
+}
+
+// Decision explains the result of TestAgent, see RobotsData.Explain.
+type Decision struct {
+	Allowed bool
+	Path    string // Path as it was compared, normalized
+
+	// AllowAll and DisallowAll are set when the decision does not depend on
+	// rules, because the robots.txt response was a client error or a server
+	// error. The other fields are empty then.
+	AllowAll    bool
+	DisallowAll bool
+
+	GroupID    string // User-agent of the chosen group, "" if none applies
+	Selection  Selection
+	MatchMode  MatchMode
+	Candidates []Match // Rules matching the path, in source order
+	Winner     *Match  // Deciding rule, one of Candidates, or nil if no rule matched
+}
+
+// Explain reports how TestAgent decides on path for agent: which group was
+// chosen and why, which rules matched and which one won.
+func (r *RobotsData) Explain(path, agent string) Decision {
+	if r.allowAll {
+		return Decision{Allowed: true, Path: path, AllowAll: true}
+	}
+	if r.disallowAll {
+		return Decision{Allowed: false, Path: path, DisallowAll: true}
+	}
+
+	id, g := r.FindGroupWithGroupId(agent)
+	d := Decision{Path: normalizePath(path), MatchMode: g.MatchMode}
+	switch {
+	case g == emptyGroup:
+		d.Selection = SelectNone
+	case id == AnyGroupId:
+		d.GroupID, d.Selection = id, SelectDefault
+	default:
+		d.GroupID, d.Selection = id, SelectAgent
+	}
+
+	winner, wi := g.findRule(d.Path), -1
+	for _, rl := range g.rules {
+		l, ok := g.matchLength(rl, d.Path)
+		if !ok {
+			continue
+		}
+		if rl == winner {
+			wi = len(d.Candidates)
+		}
+		d.Candidates = append(d.Candidates, Match{Rule: rl.export(), Length: l})
+	}
+	if wi >= 0 {
+		d.Winner = &d.Candidates[wi]
+	}
+	d.Allowed = winner == nil || winner.allow
+	return d
+}
+
+// matchLength reports whether rule r matches path and the length it is
+// ranked by, following findRule for the group's MatchMode.
+func (g *Group) matchLength(r *rule, path string) (int, bool) {
+	if g.MatchMode == MatchRFC9309 {
+		return r.length(), r.match(path)
+	}
+	switch {
+	case r.pattern != nil:
+		return len(r.pattern.String()), r.pattern.MatchString(path)
+	case r.path == "/":
+		return 1, true
+	}
+	return len(r.path), strings.HasPrefix(path, r.path)
+}
+
+// String returns a readable, multi-line account of the decision.
+func (d Decision) String() string {
+	var b strings.Builder
+	verdict := "disallowed"
+	if d.Allowed {
+		verdict = "allowed"
+	}
+	fmt.Fprintf(&b, "%s %s", d.Path, verdict)
+	switch {
+	case d.AllowAll:
+		b.WriteString(": everything is allowed\n")
+		return b.String()
+	case d.DisallowAll:
+		b.WriteString(": everything is disallowed\n")
+		return b.String()
+	case d.Selection == SelectNone:
+		b.WriteString(": no group applies\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, " by group %q (%s)\n", d.GroupID, d.Selection)
+	for i := range d.Candidates {
+		m := &d.Candidates[i]
+		mark := " "
+		if m == d.Winner {
+			mark = "*"
+		}
+		kind := "Disallow"
+		if m.Allow {
+			kind = "Allow"
+		}
+		fmt.Fprintf(&b, "%s line %d: %s: %s (length %d)\n", mark, m.Line, kind, m.Pattern, m.Length)
+	}
+	return b.String()
+}
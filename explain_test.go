@@ -0,0 +1,82 @@
+package robotstxt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`User-agent: *
+Disallow: /
+
+User-agent: googlebot
+Disallow: /private
+Allow: /private/open
+Disallow: /*.pdf$
+`)
+	require.NoError(t, err)
+
+	d := r.Explain("/private/open/a.pdf", "Googlebot/2.1")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, "googlebot", d.GroupID)
+	assert.Equal(t, SelectAgent, d.Selection)
+	assert.Equal(t, []Match{
+		{Rule{Pattern: "/private", Line: 5}, 8},
+		{Rule{Pattern: "/private/open", Allow: true, Line: 6}, 13},
+		{Rule{Pattern: "/*.pdf$", Wildcard: true, Anchored: true, Line: 7}, 9},
+	}, d.Candidates)
+	require.NotNil(t, d.Winner)
+	assert.Equal(t, 6, d.Winner.Line)
+	assert.Equal(t, "/private/open/a.pdf allowed by group \"googlebot\" (agent)\n"+
+		"  line 5: Disallow: /private (length 8)\n"+
+		"* line 6: Allow: /private/open (length 13)\n"+
+		"  line 7: Disallow: /*.pdf$ (length 9)\n", d.String())
+
+	r.SetMatchMode(MatchRFC9309)
+	d = r.Explain("/private/open/a.pdf", "Googlebot")
+	assert.Equal(t, MatchRFC9309, d.MatchMode)
+	assert.Equal(t, 7, d.Candidates[2].Length)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 6, d.Winner.Line)
+	d = r.Explain("/private/a.pdf", "Googlebot")
+	assert.Equal(t, []int{8, 7}, []int{d.Candidates[0].Length, d.Candidates[1].Length})
+	assert.Equal(t, 5, d.Winner.Line)
+
+	d = r.Explain("/caf%c3%a9", "otherbot")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, "/caf%C3%A9", d.Path)
+	assert.Equal(t, AnyGroupId, d.GroupID)
+	assert.Equal(t, SelectDefault, d.Selection)
+	assert.Equal(t, 2, d.Winner.Line)
+
+	for _, path := range []string{"/", "/private", "/private/open/a.pdf", "/x.pdf"} {
+		for _, agent := range []string{"googlebot", "bingbot"} {
+			assert.Equal(t, r.TestAgent(path, agent), r.Explain(path, agent).Allowed, "%s %s", agent, path)
+		}
+	}
+}
+
+func TestExplainSpecial(t *testing.T) {
+	t.Parallel()
+	r, err := FromStatusAndString(404, "")
+	require.NoError(t, err)
+	d := r.Explain("/x", "bot")
+	assert.Equal(t, Decision{Allowed: true, Path: "/x", AllowAll: true}, d)
+	assert.Equal(t, "/x allowed: everything is allowed\n", d.String())
+
+	r, err = FromStatusAndString(503, "")
+	require.NoError(t, err)
+	assert.Equal(t, Decision{Path: "/x", DisallowAll: true}, r.Explain("/x", "bot"))
+
+	r, err = FromString("User-agent: a\nDisallow: /")
+	require.NoError(t, err)
+	d = r.Explain("/x", "bot")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, SelectNone, d.Selection)
+	assert.Equal(t, "", d.GroupID)
+	assert.Nil(t, d.Winner)
+	assert.Empty(t, d.Candidates)
+}
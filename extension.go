@@ -0,0 +1,210 @@
+package robotstxt
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Scope tells what an extension directive applies to.
+type Scope int
+
+const (
+	// ScopeGroup directives are members of the current group, like
+	// Crawl-delay. Before any User-agent they belong to the "*" group.
+	ScopeGroup Scope = iota
+	// ScopeGlobal directives apply to the whole file, like Sitemap.
+	ScopeGlobal
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeGroup:
+		return "group"
+	case ScopeGlobal:
+		return "global"
+	}
+	return fmt.Sprintf("Scope(%d)", int(s))
+}
+
+// Extension describes a directive this package does not know, so that it
+// is parsed along with the rest of the file, see Registry.
+type Extension struct {
+	Name    string   // Name of the directive, case-insensitive
+	Aliases []string // Other spellings of the name
+	Scope   Scope
+
+	// Parse converts the value of a line, its result is Directive.Parsed.
+	// Lines with errors are reported and dropped. If Parse is nil, the
+	// value is kept as a string.
+	Parse func(value string) (interface{}, error)
+}
+
+// parse returns the Directive.Parsed of a line of e with value val.
+func (e *Extension) parse(val string) (interface{}, error) {
+	if e.Parse == nil {
+		return val, nil
+	}
+	return e.Parse(val)
+}
+
+// Registry holds the extensions used when parsing, see
+// ParseOptions.Extensions. Register every extension before parsing, a
+// Registry may be shared by concurrent parsers after that.
+type Registry struct {
+	byKey map[string]*Extension // By lower case name and alias
+}
+
+// NewRegistry returns a Registry with the given extensions.
+func NewRegistry(exts ...Extension) (*Registry, error) {
+	reg := &Registry{byKey: make(map[string]*Extension)}
+	for _, e := range exts {
+		if err := reg.Register(e); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// Register adds e. Names and aliases must not be empty, taken by another
+// extension or known to this package.
+func (reg *Registry) Register(e Extension) error {
+	if reg.byKey == nil {
+		reg.byKey = make(map[string]*Extension)
+	}
+	keys := append([]string{e.Name}, e.Aliases...)
+	for _, k := range keys {
+		k = strings.ToLower(k)
+		switch {
+		case k == "" || strings.ContainsAny(k, ":#\r\n") || strings.IndexFunc(k, isSpace) >= 0:
+			return fmt.Errorf("robotstxt: invalid extension name %q", k)
+		case isKnownDirective(k):
+			return fmt.Errorf("robotstxt: %q is a built-in directive", k)
+		case reg.byKey[k] != nil:
+			return fmt.Errorf("robotstxt: extension %q is registered already", k)
+		}
+	}
+	if e.Scope != ScopeGroup && e.Scope != ScopeGlobal {
+		return errors.New("robotstxt: invalid extension scope " + e.Scope.String())
+	}
+	ext := e
+	for _, k := range keys {
+		reg.byKey[strings.ToLower(k)] = &ext
+	}
+	return nil
+}
+
+// lookup returns the extension of a directive key, or nil.
+func (reg *Registry) lookup(key string) *Extension {
+	if reg == nil {
+		return nil
+	}
+	return reg.byKey[strings.ToLower(key)]
+}
+
+func isKnownDirective(key string) bool {
+	_, alias := directiveAliases[key]
+	return alias || containsString(knownDirectives, key)
+}
+
+// Directive is a line of an extension or an unknown directive.
+type Directive struct {
+	Name  string // Name of the extension, or the key in lower case if unknown
+	Key   string // Key as written
+	Value string // Value as written, without comment and surrounding whitespace
+	Line  int
+
+	// Parsed is the result of Extension.Parse. It is nil for unknown
+	// directives, and after JSON until RobotsData.ParseExtensions.
+	Parsed interface{}
+}
+
+// Extension returns the lines of the group-scoped extension called name, in
+// source order.
+func (g *Group) Extension(name string) []Directive {
+	return findDirectives(g.extensions, name)
+}
+
+// Extension returns the lines of the global extension called name, in
+// source order.
+func (r *RobotsData) Extension(name string) []Directive {
+	return findDirectives(r.extensions, name)
+}
+
+// Unknown returns the lines of directives that are neither known to this
+// package nor registered as extensions, in source order.
+func (r *RobotsData) Unknown() []Directive {
+	return r.unknown
+}
+
+// ParseExtensions sets Directive.Parsed of the extension lines of r with the
+// extensions of reg, as the parser does. JSON keeps the lines but not what
+// Parse made of them, so call it after restoring r from JSON. Lines whose
+// extension is not in reg are left alone. Lines reg rejects get a nil Parsed,
+// their errors are returned in a *ParseError.
+func (r *RobotsData) ParseExtensions(reg *Registry) error {
+	var errs []error
+	parse := func(ds []Directive) {
+		for i := range ds {
+			ext := reg.lookup(ds[i].Key)
+			if ext == nil {
+				continue
+			}
+			var err error
+			if ds[i].Parsed, err = ext.parse(ds[i].Value); err != nil {
+				errs = append(errs, fmt.Errorf("line %d: %s value %q: %v", ds[i].Line, ds[i].Key, ds[i].Value, err))
+			}
+		}
+	}
+	parse(r.extensions)
+	agents := make([]string, 0, len(r.groups))
+	for a := range r.groups {
+		agents = append(agents, a)
+	}
+	sort.Strings(agents)
+	for _, a := range agents {
+		parse(r.groups[a].extensions)
+	}
+	if len(errs) > 0 {
+		return newParseError(errs, nil)
+	}
+	return nil
+}
+
+// directivesJSON returns ds in the form kept in JSON, without Parsed, which
+// may not survive encoding.
+func directivesJSON(ds []Directive) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(ds))
+	for i, d := range ds {
+		out[i] = map[string]interface{}{"name": d.Name, "key": d.Key, "value": d.Value, "line": d.Line}
+	}
+	return out
+}
+
+// directivesFromJSON is the reverse of directivesJSON.
+func directivesFromJSON(v interface{}) []Directive {
+	list, _ := v.([]interface{})
+	var ds []Directive
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		d := Directive{}
+		d.Name, _ = m["name"].(string)
+		d.Key, _ = m["key"].(string)
+		d.Value, _ = m["value"].(string)
+		line, _ := m["line"].(float64)
+		d.Line = int(line)
+		ds = append(ds, d)
+	}
+	return ds
+}
+
+func findDirectives(ds []Directive, name string) []Directive {
+	var found []Directive
+	for _, d := range ds {
+		if strings.EqualFold(d.Name, name) {
+			found = append(found, d)
+		}
+	}
+	return found
+}
@@ -0,0 +1,156 @@
+package robotstxt
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const robotsExtensions = `User-agent: *
+Noindex: /drafts
+X-Max-Pages: 100
+Disallow: /private
+
+User-agent: other
+Max-Pages: lots
+X-Vendor-Thing: on # comment
+
+X-Contact: ops@example.com
+`
+
+func newTestRegistry(t *testing.T) *Registry {
+	reg, err := NewRegistry(
+		Extension{Name: "Noindex", Scope: ScopeGroup},
+		Extension{Name: "X-Max-Pages", Aliases: []string{"Max-Pages"}, Scope: ScopeGroup,
+			Parse: func(v string) (interface{}, error) { return strconv.Atoi(v) }},
+		Extension{Name: "X-Contact", Scope: ScopeGlobal},
+	)
+	require.NoError(t, err)
+	return reg
+}
+
+func TestExtensions(t *testing.T) {
+	t.Parallel()
+	reg := newTestRegistry(t)
+	r, err := FromReader(strings.NewReader(robotsExtensions), ParseOptions{Extensions: reg})
+	require.NoError(t, err)
+
+	g := r.FindGroup("bot")
+	assert.Equal(t, []Directive{{Name: "Noindex", Key: "Noindex", Value: "/drafts", Parsed: "/drafts", Line: 2}}, g.Extension("noindex"))
+	assert.Equal(t, []Directive{{Name: "X-Max-Pages", Key: "X-Max-Pages", Value: "100", Parsed: 100, Line: 3}}, g.Extension("X-Max-Pages"))
+	assert.False(t, g.Test("/private"))
+	assert.Empty(t, g.Extension("X-Contact"))
+
+	// The invalid value is dropped.
+	other := r.FindGroup("other")
+	assert.Empty(t, other.Extension("X-Max-Pages"))
+
+	assert.Equal(t, []Directive{{Name: "X-Contact", Key: "X-Contact", Value: "ops@example.com", Parsed: "ops@example.com", Line: 10}}, r.Extension("x-contact"))
+	assert.Empty(t, r.Extension("Noindex"))
+
+	// Unregistered directives are kept as they were written.
+	assert.Equal(t, []Directive{{Name: "x-vendor-thing", Key: "X-Vendor-Thing", Value: "on", Line: 8}}, r.Unknown())
+
+	assert.Equal(t, `User-agent: other
+Disallow:
+
+User-agent: *
+Disallow: /private
+Noindex: /drafts
+X-Max-Pages: 100
+
+X-Contact: ops@example.com
+`, r.String())
+
+	// JSON keeps the lines, ParseExtensions restores their parsed values.
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	restored := &RobotsData{}
+	require.NoError(t, json.Unmarshal(data, restored))
+	assert.Equal(t, r.Unknown(), restored.Unknown())
+	assert.Equal(t, []Directive{{Name: "X-Max-Pages", Key: "X-Max-Pages", Value: "100", Line: 3}}, restored.FindGroup("bot").Extension("X-Max-Pages"))
+	require.NoError(t, restored.ParseExtensions(reg))
+	assert.Equal(t, g.Extension("X-Max-Pages"), restored.FindGroup("bot").Extension("X-Max-Pages"))
+	assert.Equal(t, r.Extension("X-Contact"), restored.Extension("X-Contact"))
+	assert.Equal(t, r.String(), restored.String())
+
+	strict, err := NewRegistry(Extension{Name: "Noindex", Parse: func(v string) (interface{}, error) {
+		return nil, strconv.ErrSyntax
+	}})
+	require.NoError(t, err)
+	err = restored.ParseExtensions(strict)
+	require.IsType(t, &ParseError{}, err)
+	assert.Len(t, err.(*ParseError).Errs, 1)
+	assert.Nil(t, restored.FindGroup("bot").Extension("Noindex")[0].Parsed)
+}
+
+func TestLintExtensions(t *testing.T) {
+	t.Parallel()
+	codes := func(diags []Diagnostic) map[int]string {
+		m := make(map[int]string)
+		for _, d := range diags {
+			m[d.Line] = d.Code
+		}
+		return m
+	}
+
+	// Registered directives are checked with their parsers, the rest are
+	// unknown.
+	l := NewLinter()
+	l.Extensions = newTestRegistry(t)
+	assert.Equal(t, map[int]string{7: CodeInvalidExtension, 8: CodeUnknownDirective}, codes(l.Lint([]byte(robotsExtensions))))
+
+	assert.Equal(t, map[int]string{
+		1: CodeRuleOutsideGroup,
+		2: CodeUnknownDirective,
+	}, codes(l.Lint([]byte("Noindex: /a\nNoindx: /b\nX-Contact: ops@example.com\nUser-agent: *\n"))))
+
+	// A registered name is not a misspelling of a known one.
+	const sitemaps = "User-agent: *\nDisallow:\nSitemaps: https://example.com/a.xml\n"
+	l.Extensions = nil
+	assert.Equal(t, map[int]string{3: CodeDirectiveTypo}, codes(l.Lint([]byte(sitemaps))))
+	l.Extensions, _ = NewRegistry(Extension{Name: "Sitemaps", Scope: ScopeGlobal})
+	assert.Empty(t, l.Lint([]byte(sitemaps)))
+}
+
+func TestExtensionsUnregistered(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(robotsExtensions)
+	require.NoError(t, err)
+	assert.Empty(t, r.FindGroup("bot").Extension("Noindex"))
+	var keys []string
+	for _, d := range r.Unknown() {
+		keys = append(keys, d.Key)
+	}
+	assert.Equal(t, []string{"Noindex", "X-Max-Pages", "Max-Pages", "X-Vendor-Thing", "X-Contact"}, keys)
+	// Unknown lines are not group members.
+	assert.Equal(t, "User-agent: *\nDisallow: /private\n", r.FindGroup("bot").String())
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+	reg := &Registry{}
+	require.NoError(t, reg.Register(Extension{Name: "Noindex"}))
+	for _, e := range []Extension{
+		{Name: ""},
+		{Name: "No index"},
+		{Name: "x:y"},
+		{Name: "NOINDEX"},
+		{Name: "Other", Aliases: []string{"noindex"}},
+		{Name: "Disallow"},
+		{Name: "crawldelay"},
+		{Name: "Other", Scope: Scope(7)},
+	} {
+		assert.Error(t, reg.Register(e), e.Name)
+	}
+	assert.True(t, reg.lookup("other") == nil)
+	assert.Equal(t, "Noindex", reg.lookup("NoIndex").Name)
+
+	_, err := NewRegistry(Extension{Name: "a"}, Extension{Name: "A"})
+	assert.Error(t, err)
+	assert.Equal(t, "global", ScopeGlobal.String())
+}
@@ -0,0 +1,207 @@
+package robotstxt
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// MaxRedirects is the number of redirects a Fetcher follows. From RFC 9309
+// section 2.3.1.2:
+// The crawlers SHOULD follow at least five consecutive redirects, even
+// across authorities (for example, hosts in the case of HTTP).
+const MaxRedirects = 5
+
+// DefaultFetchTimeout limits a whole fetch when Fetcher.Client is nil.
+const DefaultFetchTimeout = 30 * time.Second
+
+// Decoder wraps a response body encoded with some Content-Encoding.
+type Decoder func(io.Reader) (io.ReadCloser, error)
+
+// Fetcher downloads and parses robots.txt files. The zero value is usable.
+type Fetcher struct {
+	// Client sends the requests. Its CheckRedirect is replaced to follow
+	// MaxRedirects redirects. If nil, a client with DefaultFetchTimeout is
+	// used.
+	Client *http.Client
+
+	// UserAgent is sent with every request, if not empty.
+	UserAgent string
+
+	// Policy interprets response statuses and network errors. If nil,
+	// RFC9309StatusPolicy is used.
+	Policy *StatusPolicy
+
+	// Options limit the size of the file.
+	Options ParseOptions
+
+	// Decoders adds or replaces decoders of Content-Encoding values, which
+	// are advertised in Accept-Encoding. Keys are lower case. Decoders of
+	// "gzip", "deflate" and "br" are built in. Responses in other encodings
+	// are an error.
+	Decoders map[string]Decoder
+}
+
+// RobotsURL returns the URL of the robots.txt file that applies to pageURL:
+// the path "/robots.txt" on the same scheme, host and port.
+func RobotsURL(pageURL string) (*url.URL, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme != "http" && scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("robotstxt: %q is not an absolute http or https URL", pageURL)
+	}
+	return &url.URL{Scheme: scheme, Host: strings.ToLower(u.Host), Path: "/robots.txt"}, nil
+}
+
+// Fetch downloads and parses the robots.txt file that applies to pageURL.
+// The Origin of the result is the robots.txt URL before redirects, because
+// that is where the rules apply. Network errors are handled as the Policy
+// says, except for the cancellation of ctx, which is returned.
+func (f *Fetcher) Fetch(ctx context.Context, pageURL string) (*RobotsData, error) {
+	u, err := RobotsURL(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	r, _, err := f.fetch(ctx, u, nil, nil)
+	return r, err
+}
+
+// fetch requests robotsURL with the extra header and returns the parsed
+// result along with the response, which has been read and closed already.
+// The response is nil on network errors. The result is nil if the response
+// to a conditional request is 304 "Not Modified". If raw is not nil, the
+// decoded body as far as it was read is copied to it.
+func (f *Fetcher) fetch(ctx context.Context, robotsURL *url.URL, header http.Header, raw io.Writer) (*RobotsData, *http.Response, error) {
+	policy := f.policy()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	// Setting Accept-Encoding turns off the transparent gzip of the
+	// transport, so all encodings are handled the same way below.
+	req.Header.Set("Accept-Encoding", strings.Join(f.encodings(), ", "))
+
+	res, err := f.client().Do(req)
+	if err != nil {
+		r := fromAction(policy.NetworkError)
+		if r == nil || ctx.Err() != nil {
+			return nil, nil, err
+		}
+		r.Origin = robotsURL
+		r.FetchedAt = time.Now()
+		return r, nil, nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && (header.Get("If-None-Match") != "" || header.Get("If-Modified-Since") != "") {
+		return nil, res, nil
+	}
+
+	// Bodies of error pages are not read, their encoding does not matter.
+	body := io.NopCloser(strings.NewReader(""))
+	if policy.Action(res.StatusCode) == StatusParse {
+		if body, err = f.decode(res); err != nil {
+			return nil, res, err
+		}
+	}
+	defer body.Close()
+	var src io.Reader = body
+	if raw != nil {
+		src = io.TeeReader(body, raw)
+	}
+	r, err := fromResponse(res, src, policy, f.Options)
+	if err != nil {
+		return nil, res, err
+	}
+	// Let the connection be reused, within reason.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+	return r, res, nil
+}
+
+func (f *Fetcher) policy() StatusPolicy {
+	if f.Policy == nil {
+		return RFC9309StatusPolicy()
+	}
+	return *f.Policy
+}
+
+// client returns a copy of the configured client that follows at most
+// MaxRedirects redirects and returns the last redirect response after that,
+// so that the status policy decides what it means.
+func (f *Fetcher) client() *http.Client {
+	c := &http.Client{Timeout: DefaultFetchTimeout}
+	if f.Client != nil {
+		cc := *f.Client
+		c = &cc
+	}
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > MaxRedirects {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	return c
+}
+
+// encodings returns the supported content codings, built in first.
+func (f *Fetcher) encodings() []string {
+	encs := []string{"gzip", "deflate", "br"}
+	extra := make([]string, 0, len(f.Decoders))
+	for name := range f.Decoders {
+		if name != "gzip" && name != "deflate" && name != "br" && f.Decoders[name] != nil {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(encs, extra...)
+}
+
+// decode returns the body of res without its Content-Encoding.
+func (f *Fetcher) decode(res *http.Response) (io.ReadCloser, error) {
+	enc := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+	if d := f.Decoders[enc]; d != nil {
+		return d(res.Body)
+	}
+	switch enc {
+	case "", "identity":
+		return io.NopCloser(res.Body), nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(res.Body)
+	case "deflate":
+		return newDeflateReader(res.Body)
+	case "br":
+		return io.NopCloser(brotli.NewReader(res.Body)), nil
+	}
+	return nil, errors.New("robotstxt: unsupported Content-Encoding " + enc)
+}
+
+// newDeflateReader reads the "deflate" coding, which is zlib format by the
+// HTTP spec, but raw deflate data from some servers.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -2,7 +2,10 @@ module github.com/temoto/robotstxt
 
 go 1.18
 
-require github.com/stretchr/testify v1.3.0
+require (
+	github.com/andybalholm/brotli v1.1.0
+	github.com/stretchr/testify v1.3.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
@@ -0,0 +1,310 @@
+package robotstxt
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Codes of the checks only done by the linter, see LintRules.
+const (
+	CodeDirectiveTypo      = "directive-typo"
+	CodeRuleOutsideGroup   = "rule-outside-group"
+	CodeRelativeSitemap    = "relative-sitemap"
+	CodeDuplicateGroup     = "duplicate-group"
+	CodeEmptyFile          = "empty-file"
+	CodeHTMLDocument       = "html-document"
+	CodeDuplicateUserAgent = "duplicate-user-agent"
+)
+
+// LintRule describes one check of the linter.
+type LintRule struct {
+	Code        string
+	Severity    Severity
+	Description string
+}
+
+// LintRules is the catalogue of checks done by Linter. It includes the
+// diagnostics reported by Parse, so those can be disabled as well.
+var LintRules = []LintRule{
+	{CodeInvalidUTF8, SeverityWarning, "The file is not valid UTF-8."},
+	{CodeMissingColon, SeverityWarning, "A directive is not followed by a colon."},
+	{CodeHTML, SeverityWarning, "A line starts with HTML markup, which is skipped."},
+	{CodeWhitespaceInValue, SeverityWarning, "A user-agent, path or URL value contains whitespace."},
+	{CodeExtraFields, SeverityWarning, "A Clean-param value has more than a parameter list and a path, or a Request-rate value more than a rate."},
+	{CodeInvalidCrawlDelay, SeverityError, "A Crawl-delay value is not a non-negative number."},
+	{CodeInvalidRequestRate, SeverityError, "A Request-rate value is not a positive number of requests per positive period."},
+	{CodeInvalidVisitTime, SeverityError, "A Visit-time value is not a window of UTC times like 0100-0545."},
+	{CodeInvalidPattern, SeverityError, "A path pattern cannot be compiled."},
+	{CodeInvalidUsage, SeverityWarning, "A Content-Signal or Content-Usage item is not a category set to yes or no."},
+	{CodeInvalidExtension, SeverityError, "The value of a registered extension directive cannot be parsed."},
+	{CodeUnknownDirective, SeverityInfo, "A directive is not known to this package."},
+	{CodeDirectiveTypo, SeverityWarning, "A directive name looks like a misspelling of a known one."},
+	{CodeRuleOutsideGroup, SeverityWarning, "A group member line comes before any User-agent line."},
+	{CodeRelativeSitemap, SeverityWarning, "A Sitemap value is not an absolute URL."},
+	{CodeDuplicateGroup, SeverityWarning, "A user-agent is named in more than one group, the groups are merged."},
+	{CodeDuplicateUserAgent, SeverityInfo, "A user-agent is named twice in the same group."},
+	{CodeEmptyFile, SeverityWarning, "The file has no directives, everything is allowed."},
+	{CodeHTMLDocument, SeverityError, "The file is an HTML document, not robots.txt."},
+}
+
+// Spellings of directive names that the parser accepts, but that are not
+// the standard ones.
+var directiveAliases = map[string]string{
+	"useragent":    "user-agent",
+	"usser-agent":  "user-agent",
+	"ser-agent":    "user-agent",
+	"crawldelay":   "crawl-delay",
+	"requestrate":  "request-rate",
+	"visittime":    "visit-time",
+	"cleanparam":   "clean-param",
+	"clean-params": "clean-param",
+}
+
+var knownDirectives = []string{"user-agent", "allow", "disallow", "sitemap", "host", "crawl-delay", "request-rate", "visit-time", "clean-param", "content-signal", "content-usage"}
+
+// Linter checks robots.txt files for common mistakes. The zero value is not
+// usable, create one with NewLinter.
+type Linter struct {
+	// Extensions are the extension directives the file may use, as in
+	// ParseOptions. Their lines are checked with Extension.Parse instead of
+	// being reported as unknown or misspelled.
+	Extensions *Registry
+
+	disabled map[string]bool
+}
+
+// NewLinter returns a Linter with all checks of LintRules enabled.
+func NewLinter() *Linter {
+	return &Linter{disabled: make(map[string]bool)}
+}
+
+// Enable turns on the checks with the given codes.
+func (l *Linter) Enable(codes ...string) {
+	for _, c := range codes {
+		delete(l.disabled, c)
+	}
+}
+
+// Disable turns off the checks with the given codes.
+func (l *Linter) Disable(codes ...string) {
+	for _, c := range codes {
+		l.disabled[c] = true
+	}
+}
+
+// Enabled reports whether the check with the given code is on.
+func (l *Linter) Enabled(code string) bool {
+	return !l.disabled[code]
+}
+
+// Lint checks robots.txt content with all checks enabled.
+func Lint(body []byte) []Diagnostic {
+	return NewLinter().Lint(body)
+}
+
+// LintData checks parsed or built data with all checks enabled.
+func LintData(r *RobotsData) []Diagnostic {
+	return NewLinter().LintData(r)
+}
+
+// Lint checks robots.txt content and returns the problems found, ordered by
+// position. Spelling and order are only known from the source, use LintData
+// to check parsed or built RobotsData.
+func (l *Linter) Lint(body []byte) []Diagnostic {
+	sc := newByteScanner("bytes", true)
+	sc.feed(body, true)
+	lines := sc.scanAll()
+	parser := newParser(lines, sc.report)
+	parser.exts = l.Extensions
+	parser.parseAll()
+
+	report := sc.report
+	begin := token.Position{Line: 1, Column: 1}
+	if looksLikeHTML(body) {
+		report(begin, SeverityError, CodeHTMLDocument, "file is an HTML document")
+	}
+	if len(lines) == 0 {
+		report(begin, SeverityWarning, CodeEmptyFile, "file has no directives")
+	}
+
+	var (
+		typos     = make(map[int]bool) // Lines with a misspelled directive
+		group     int                  // Number of the current group, 0 before the first
+		inAgents  bool                 // Previous group line was a User-agent
+		seenAgent = make(map[string]token.Position)
+		agentGrp  = make(map[string]int)
+	)
+	for _, ln := range lines {
+		key := strings.ToLower(ln.key)
+		if ext := l.Extensions.lookup(key); ext != nil {
+			if ext.Scope == ScopeGroup {
+				inAgents = false
+				if group == 0 {
+					report(ln.pos, SeverityWarning, CodeRuleOutsideGroup, fmt.Sprintf("%s before any User-agent applies to all user-agents", ln.key))
+				}
+			}
+			continue
+		}
+		if canonical, ok := directiveAliases[key]; ok {
+			report(ln.pos, SeverityWarning, CodeDirectiveTypo, fmt.Sprintf("%q looks like a misspelling of %q", ln.key, canonical))
+			key = canonical
+		} else if suggestion := suggestDirective(key); suggestion != "" {
+			report(ln.pos, SeverityWarning, CodeDirectiveTypo, fmt.Sprintf("%q looks like a misspelling of %q", ln.key, suggestion))
+			typos[ln.pos.Line] = true
+		}
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				group++
+				inAgents = true
+			}
+			agent := strings.ToLower(ln.value)
+			if agent == "" {
+				continue
+			}
+			if pos, ok := seenAgent[agent]; ok {
+				if agentGrp[agent] == group {
+					report(ln.pos, SeverityInfo, CodeDuplicateUserAgent, fmt.Sprintf("user-agent %q is already named on line %d", ln.value, pos.Line))
+				} else {
+					report(ln.pos, SeverityWarning, CodeDuplicateGroup, fmt.Sprintf("user-agent %q already has a group on line %d", ln.value, pos.Line))
+				}
+				continue
+			}
+			seenAgent[agent] = ln.pos
+			agentGrp[agent] = group
+
+		case "allow", "disallow", "crawl-delay", "request-rate", "visit-time", "clean-param", "content-signal", "content-usage":
+			inAgents = false
+			if group == 0 {
+				report(ln.pos, SeverityWarning, CodeRuleOutsideGroup, fmt.Sprintf("%s before any User-agent applies to all user-agents", ln.key))
+			}
+
+		case "sitemap":
+			if ln.value != "" && !isAbsoluteURL(ln.value) {
+				report(ln.pos, SeverityWarning, CodeRelativeSitemap, fmt.Sprintf("Sitemap %q is not an absolute URL", ln.value))
+			}
+		}
+	}
+
+	diags := make([]Diagnostic, 0, len(sc.diags))
+	for _, d := range sc.diags {
+		// A misspelled directive is unknown too, saying it twice is noise.
+		if d.Code == CodeUnknownDirective && typos[d.Line] {
+			continue
+		}
+		if l.Enabled(d.Code) {
+			diags = append(diags, d)
+		}
+	}
+	return sortDiagnostics(diags)
+}
+
+// LintData checks the parts of RobotsData that keep their meaning after
+// parsing: negative Crawl-delay, relative Sitemap URLs and a policy without
+// any directives. The data has no source positions, so Line and Column of
+// the diagnostics are 0. Diagnostics are ordered by user-agent, then
+// sitemaps.
+func (l *Linter) LintData(r *RobotsData) []Diagnostic {
+	var diags []Diagnostic
+	report := func(severity Severity, code, msg string) {
+		if l.Enabled(code) {
+			diags = append(diags, newDiagnostic(token.Position{}, severity, code, msg))
+		}
+	}
+
+	if len(r.groups) == 0 && len(r.Sitemaps) == 0 && r.Host == "" {
+		report(SeverityWarning, CodeEmptyFile, "policy has no directives")
+	}
+	agents := make([]string, 0, len(r.groups))
+	for a := range r.groups {
+		agents = append(agents, a)
+	}
+	sort.Strings(agents)
+	for _, a := range agents {
+		if d := r.groups[a].CrawlDelay; d < 0 {
+			report(SeverityError, CodeInvalidCrawlDelay, fmt.Sprintf("Crawl-delay %v of user-agent %q is negative", d, a))
+		}
+	}
+	for _, s := range r.Sitemaps {
+		if !isAbsoluteURL(s) {
+			report(SeverityWarning, CodeRelativeSitemap, fmt.Sprintf("Sitemap %q is not an absolute URL", s))
+		}
+	}
+	return diags
+}
+
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+// suggestDirective returns the known directive an unknown key is probably a
+// misspelling of, or "" if it does not look like one.
+func suggestDirective(key string) string {
+	for _, d := range knownDirectives {
+		if key == d {
+			return ""
+		}
+	}
+	if _, ok := directiveAliases[key]; ok {
+		return ""
+	}
+	for _, d := range knownDirectives {
+		// Short names are only one edit apart from too many other words.
+		limit := 1
+		if len(d) > 5 {
+			limit = 2
+		}
+		if editDistance(key, d) <= limit {
+			return d
+		}
+	}
+	return ""
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// looksLikeHTML reports whether body starts like an HTML document.
+func looksLikeHTML(body []byte) bool {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+	body = bytes.TrimSpace(body)
+	if len(body) > 64 {
+		body = body[:64]
+	}
+	body = bytes.ToLower(body)
+	return bytes.HasPrefix(body, []byte("<!doctype html")) || bytes.HasPrefix(body, []byte("<html"))
+}
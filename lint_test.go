@@ -0,0 +1,113 @@
+package robotstxt
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name   string
+		input  string
+		expect []string // "line:code"
+	}{
+		{"clean", robotsText001, nil},
+		{"empty", "", []string{"1:" + CodeEmptyFile}},
+		{"only-comments", "# nothing here\n", []string{"1:" + CodeEmptyFile}},
+		{"alias-typo", "Usser-agent: *\nDisallow: /", []string{"1:" + CodeDirectiveTypo}},
+		{"ser-agent", "ser-agent: *\nDisallow: /", []string{"1:" + CodeDirectiveTypo}},
+		{"unknown-typo", "User-agent: *\nDissallow: /", []string{"2:" + CodeDirectiveTypo}},
+		{"unknown", "User-agent: *\nNoindex: /", []string{"2:" + CodeUnknownDirective}},
+		{"rule-outside-group", "Disallow: /a\nUser-agent: *\nDisallow: /b", []string{"1:" + CodeRuleOutsideGroup}},
+		{"crawl-delay-outside-group", "Crawl-delay: 5\n", []string{"1:" + CodeRuleOutsideGroup}},
+		{"crawl-delay-syntax", "User-agent: bot\nCrawl-delay: bad-time-value", []string{"2:" + CodeInvalidCrawlDelay}},
+		{"crawl-delay-negative", "User-agent: bot\nCrawl-delay: -1", []string{"2:" + CodeInvalidCrawlDelay}},
+		{"crawl-delay-inf", "User-agent: bot\nCrawl-delay: -inf", []string{"2:" + CodeInvalidCrawlDelay}},
+		{"request-rate-syntax", "User-agent: bot\nRequest-rate: 5", []string{"2:" + CodeInvalidRequestRate}},
+		{"request-rate-typo", "User-agent: bot\nRequest-rte: 1/5", []string{"2:" + CodeDirectiveTypo}},
+		{"visit-time-syntax", "User-agent: bot\nVisit-time: 1-5", []string{"2:" + CodeInvalidVisitTime}},
+		{"relative-sitemap", "Sitemap: /sitemap.xml\nSitemap: https://example.com/sitemap.xml", []string{"1:" + CodeRelativeSitemap}},
+		{"duplicate-group", "User-agent: a\nDisallow: /a\n\nUser-agent: b\nUser-agent: A\nDisallow: /b", []string{"5:" + CodeDuplicateGroup}},
+		{"duplicate-agent", "User-agent: a\nUser-agent: a\nDisallow: /a", []string{"2:" + CodeDuplicateUserAgent}},
+		{"html", robotsTextJustHTML, []string{"1:" + CodeHTML, "1:" + CodeHTMLDocument, "2:" + CodeHTML, "3:" + CodeHTML, "4:" + CodeHTML, "4:" + CodeMissingColon, "4:" + CodeUnknownDirective}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var actual []string
+			for _, d := range Lint([]byte(c.input)) {
+				actual = append(actual, strconv.Itoa(d.Line)+":"+d.Code)
+			}
+			assert.Equal(t, c.expect, actual)
+		})
+	}
+}
+
+func TestLinterDisable(t *testing.T) {
+	t.Parallel()
+	const input = "Disallow: /a\nUser-agent: *\nNoindex: /b\nSitemap: /sitemap.xml"
+	l := NewLinter()
+	assert.Len(t, l.Lint([]byte(input)), 3)
+
+	l.Disable(CodeUnknownDirective, CodeRelativeSitemap)
+	assert.False(t, l.Enabled(CodeRelativeSitemap))
+	diags := l.Lint([]byte(input))
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, CodeRuleOutsideGroup, diags[0].Code)
+		assert.Equal(t, SeverityWarning, diags[0].Severity)
+	}
+
+	l.Enable(CodeRelativeSitemap)
+	assert.Len(t, l.Lint([]byte(input)), 2)
+}
+
+func TestLintData(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []Diagnostic{{Severity: SeverityWarning, Code: CodeEmptyFile, Message: "policy has no directives"}}, LintData(&RobotsData{}))
+
+	r, err := FromString("User-agent: *\nDisallow: /\n\nSitemap: https://example.com/sitemap.xml\n")
+	assert.NoError(t, err)
+	assert.Empty(t, LintData(r))
+
+	r.Sitemaps = append(r.Sitemaps, "/sitemap.xml")
+	r.SetGroups(map[string]*Group{
+		"b": {Agent: "b", CrawlDelay: -time.Second},
+		"a": {Agent: "a", CrawlDelay: -2 * time.Second},
+		"c": {Agent: "c", CrawlDelay: time.Second},
+	})
+	var actual []string
+	for _, d := range LintData(r) {
+		assert.Zero(t, d.Line)
+		actual = append(actual, d.Message)
+	}
+	assert.Equal(t, []string{
+		`Crawl-delay -2s of user-agent "a" is negative`,
+		`Crawl-delay -1s of user-agent "b" is negative`,
+		`Sitemap "/sitemap.xml" is not an absolute URL`,
+	}, actual)
+
+	l := NewLinter()
+	l.Disable(CodeInvalidCrawlDelay)
+	diags := l.LintData(r)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, CodeRelativeSitemap, diags[0].Code)
+	}
+}
+
+func TestLintRulesCatalogue(t *testing.T) {
+	t.Parallel()
+	seen := make(map[string]bool)
+	for _, r := range LintRules {
+		assert.False(t, seen[r.Code], "duplicate code %s", r.Code)
+		assert.NotEmpty(t, r.Description)
+		seen[r.Code] = true
+	}
+	for _, input := range []string{robotsTextJustHTML, "Usser-agent: *\nDissallow: /\nCrawl-delay: x\nDisallow /a b\nSitemap: x"} {
+		for _, d := range Lint([]byte(input)) {
+			assert.True(t, seen[d.Code], "code %s is not in LintRules", d.Code)
+		}
+	}
+}
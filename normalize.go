@@ -0,0 +1,86 @@
+package robotstxt
+
+// From RFC 9309 section 2.2.2:
+// Octets in the URI and robots.txt paths outside the range of the ASCII coded
+// character set, and those in the reserved range defined by [RFC3986], MUST
+// be percent-encoded as defined by [RFC3986] prior to comparison.
+//
+// If a percent-encoded ASCII octet is encountered in the URI, it MUST be
+// unencoded prior to comparison, unless it is a reserved character in the
+// URI as defined by [RFC3986] or the character is outside the unreserved
+// character range.
+
+const upperhex = "0123456789ABCDEF"
+
+// normalizePath brings a rule path or a tested path to the form both are
+// compared in: octets that may not appear literally in a URI are
+// percent-encoded, escapes of unreserved characters are decoded and the hex
+// digits of the remaining escapes are upper case. Reserved characters,
+// including the "*" and "$" wildcards, are left alone.
+func normalizePath(s string) string {
+	// Fast path, most paths are already in canonical form.
+	i := 0
+	for ; i < len(s); i++ {
+		if c := s[i]; c == '%' || !isURIChar(c) {
+			break
+		}
+	}
+	if i == len(s) {
+		return s
+	}
+
+	b := make([]byte, i, len(s)+8)
+	copy(b, s[:i])
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			v := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(v) {
+				b = append(b, v)
+			} else {
+				b = append(b, '%', upperhex[v>>4], upperhex[v&15])
+			}
+			i += 2
+		case c == '%' || isURIChar(c):
+			// A stray "%" is kept as is, there is nothing sensible to decode.
+			b = append(b, c)
+		default:
+			b = append(b, '%', upperhex[c>>4], upperhex[c&15])
+		}
+	}
+	return string(b)
+}
+
+// isURIChar reports whether c may appear literally in a URI, that is whether
+// it is an unreserved or a reserved character of RFC 3986.
+func isURIChar(c byte) bool {
+	if isUnreserved(c) {
+		return true
+	}
+	switch c {
+	case ':', '/', '?', '#', '[', ']', '@', // gen-delims
+		'!', '$', '&', '\'', '(', ')', '*', '+', ',', ';', '=': // sub-delims
+		return true
+	}
+	return false
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
@@ -0,0 +1,72 @@
+package robotstxt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"":                   "",
+		"/":                  "/",
+		"/plain/path?q=1":    "/plain/path?q=1",
+		"/café":              "/caf%C3%A9",
+		"/caf%c3%a9":         "/caf%C3%A9",
+		"/%63afe":            "/cafe",
+		"/a%2fb":             "/a%2Fb",
+		"/a%2Fb":             "/a%2Fb",
+		"/%7Euser":           "/~user",
+		"/my file":           "/my%20file",
+		"/a<b>":              "/a%3Cb%3E",
+		"/*.php$":            "/*.php$",
+		"/100%":              "/100%",
+		"/%zz":               "/%zz",
+		"/%2A":               "/%2A",
+		"/\x7f\x00":          "/%7F%00",
+		"/путь/%D0%BF%d1%83": "/%D0%BF%D1%83%D1%82%D1%8C/%D0%BF%D1%83",
+	}
+	for input, expect := range cases {
+		assert.Equal(t, expect, normalizePath(input), "input %q", input)
+	}
+}
+
+func TestPercentEncodingMatching(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`user-agent: *
+disallow: /café
+disallow: /a%2fb
+disallow: /%7Euser/
+allow: /%63afe/menu`)
+	require.NoError(t, err)
+
+	expectAllAgents(t, r, false, "/café")
+	expectAllAgents(t, r, false, "/caf%C3%A9")
+	expectAllAgents(t, r, false, "/caf%c3%a9/menu")
+	expectAllAgents(t, r, false, "/a%2Fb")
+	expectAllAgents(t, r, false, "/a%2fb")
+	expectAllAgents(t, r, false, "/~user/index.html")
+	expectAllAgents(t, r, false, "/%7euser/index.html")
+	expectAllAgents(t, r, true, "/a/b")
+	expectAllAgents(t, r, true, "/cafe/menu")
+	expectAllAgents(t, r, true, "/%63afe/menu")
+	expectAllAgents(t, r, true, "/cafe")
+}
+
+func TestPercentEncodingWildcards(t *testing.T) {
+	t.Parallel()
+	r, err := FromString("user-agent: *\ndisallow: /*/café$")
+	require.NoError(t, err)
+	expectAllAgents(t, r, false, "/menu/café")
+	expectAllAgents(t, r, false, "/menu/caf%c3%a9")
+	expectAllAgents(t, r, true, "/menu/café/drinks")
+}
+
+func BenchmarkNormalizePath(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		normalizePath("/wp-admin/admin-ajax.php?action=heartbeat")
+	}
+}
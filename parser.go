@@ -7,6 +7,8 @@ package robotstxt
 // http://en.wikipedia.org/wiki/Robots.txt
 
 import (
+	"fmt"
+	"go/token"
 	"io"
 	"math"
 	"regexp"
@@ -27,11 +29,21 @@ const (
 	lSitemap
 	lHost
 	lCleanParam
+	lRequestRate
+	lVisitTime
+	lExtension
+	lUsage
 )
 
 type parser struct {
-	tokens []string
+	lines  []line
 	pos    int
+	report func(pos token.Position, severity Severity, code, msg string)
+	exts   *Registry // Extensions to parse, may be nil
+
+	// Directives that are not group members, filled by parseAll.
+	globals []Directive
+	unknown []Directive
 }
 
 type lineInfo struct {
@@ -41,10 +53,19 @@ type lineInfo struct {
 	vsc string         // String value was concatenated by & symbol
 	vf  float64        // Float value of the key
 	vr  *regexp.Regexp // Regexp value of the key
+	raw string         // Path pattern as written, with the leading "/" ensured
+	rr  RequestRate    // Request-rate value
+	tw  *TimeWindow    // Visit-time value, nil if invalid
+	ext *Extension     // Extension of the line, for lExtension
+	ur  *usageRule     // Content-Signal or Content-Usage value
+	dir *Directive     // Line of an extension or unknown directive
+	pos token.Position // Position of the line
 }
 
-func newParser(tokens []string) *parser {
-	return &parser{tokens: tokens}
+// newParser creates a parser over scanned lines, report is called with the
+// diagnostics found while parsing.
+func newParser(lines []line, report func(pos token.Position, severity Severity, code, msg string)) *parser {
+	return &parser{lines: lines, report: report}
 }
 
 func parseGroupMap(groups map[string]*Group, agents []string, fun func(*Group)) {
@@ -64,17 +85,22 @@ func (p *parser) parseAll() (groups map[string]*Group, host string, sitemaps []s
 	agents := make([]string, 0, 4)
 	isEmptyGroup := true
 
-	// Reset internal fields, tokens are assigned at creation time, never change
+	// Reset internal fields, lines are assigned at creation time, never change
 	p.pos = 0
 
 	setRule := func(li *lineInfo, groups map[string]*Group, agents []string, allow bool) {
 		var r *rule
 		if li.vr != nil {
-			r = &rule{"", allow, li.vr}
+			r = &rule{allow: allow, pattern: li.vr, raw: li.raw, line: li.pos.Line}
 		} else {
-			r = &rule{li.vs, allow, nil}
+			r = &rule{path: li.vs, allow: allow, raw: li.raw, line: li.pos.Line}
 		}
-		parseGroupMap(groups, agents, func(g *Group) { g.rules = append(g.rules, r) })
+		parseGroupMap(groups, agents, func(g *Group) {
+			// A rule without a path still makes the group exist.
+			if r.raw != "" {
+				g.rules = append(g.rules, r)
+			}
+		})
 	}
 
 	for {
@@ -90,13 +116,14 @@ func (p *parser) parseAll() (groups map[string]*Group, host string, sitemaps []s
 				if !isEmptyGroup {
 					// End previous group
 					agents = make([]string, 0, 4)
-					agents = append(agents, li.vs)
-					setRule(li, groups, agents, false)
-				}
-				if len(agents) == 0 {
 					isEmptyGroup = true
 				}
-				agents = append(agents, li.vs)
+				// An agent named twice would get every rule twice.
+				if !containsString(agents, li.vs) {
+					agents = append(agents, li.vs)
+				}
+				// A group exists even if it ends up without rules.
+				parseGroupMap(groups, agents[len(agents)-1:], func(*Group) {})
 
 			case lDisallow:
 				// Error if no current group
@@ -142,6 +169,48 @@ func (p *parser) parseAll() (groups map[string]*Group, host string, sitemaps []s
 				delay := time.Duration(li.vf * float64(time.Second))
 				parseGroupMap(groups, agents, func(g *Group) { g.CrawlDelay = delay })
 
+			case lRequestRate:
+				if len(agents) == 0 {
+					agents = append(agents, "*")
+				}
+				isEmptyGroup = false
+				if li.rr.Requests > 0 {
+					rr := li.rr
+					parseGroupMap(groups, agents, func(g *Group) { g.RequestRate = rr })
+				}
+
+			case lVisitTime:
+				if len(agents) == 0 {
+					agents = append(agents, "*")
+				}
+				isEmptyGroup = false
+				if li.tw != nil {
+					tw := *li.tw
+					parseGroupMap(groups, agents, func(g *Group) { g.VisitTimes = append(g.VisitTimes, tw) })
+				}
+
+			case lExtension:
+				if li.ext.Scope == ScopeGlobal {
+					p.globals = append(p.globals, *li.dir)
+					continue
+				}
+				if len(agents) == 0 {
+					agents = append(agents, "*")
+				}
+				isEmptyGroup = false
+				parseGroupMap(groups, agents, func(g *Group) { g.extensions = append(g.extensions, *li.dir) })
+
+			case lUsage:
+				if len(agents) == 0 {
+					agents = append(agents, "*")
+				}
+				isEmptyGroup = false
+				li.ur.line = li.pos.Line
+				parseGroupMap(groups, agents, func(g *Group) { g.usageRules = append(g.usageRules, li.ur) })
+
+			case lUnknown:
+				p.unknown = append(p.unknown, *li.dir)
+
 			case lCleanParam:
 				if len(li.vsc) == 0 {
 					continue
@@ -153,7 +222,7 @@ func (p *parser) parseAll() (groups map[string]*Group, host string, sitemaps []s
 
 				isEmptyGroup = false
 				// params to clean always located in li.vs, required
-				r := &cleanParamRule{params: strings.Split(li.vsc, "&"), path: li.vs}
+				r := &cleanParamRule{params: strings.Split(li.vsc, "&"), path: li.vs, raw: li.raw, line: li.pos.Line}
 
 				// regex pattern for url always located in li.vr, if exists
 				if li.vr != nil {
@@ -168,79 +237,55 @@ func (p *parser) parseAll() (groups map[string]*Group, host string, sitemaps []s
 }
 
 func (p *parser) parseLine() (li *lineInfo, err error) {
-	t1, ok1 := p.popToken()
-	if !ok1 {
+	ln, ok := p.popLine()
+	if !ok {
 		// proper EOF
 		return nil, io.EOF
 	}
-
-	t2, ok2 := p.peekToken()
-	if !ok2 {
-		// EOF, no value associated with the token, so ignore token and return
-		return nil, io.EOF
+	defer func() {
+		if li != nil {
+			li.pos = ln.pos
+		}
+	}()
+	key, val := ln.key, ln.value
+
+	// Helper closure for values that are a single word, like user-agent
+	// names and URLs. Whitespace inside them is kept, but reported, because
+	// it usually means the line says something else than intended.
+	checkSpace := func() {
+		if strings.IndexFunc(val, isSpace) >= 0 {
+			p.report(ln.pos, SeverityWarning, CodeWhitespaceInValue, fmt.Sprintf("%s value %q contains whitespace", key, val))
+		}
 	}
 
-	// Helper closure for all string-based tokens, common behaviour:
-	// - Consume t2 token
-	// - If empty, return unknown line info
-	// - Otherwise return the specified line info
-	returnStringVal := func(t lineType) (*lineInfo, error) {
-		p.popToken()
-		if t2 != "" {
-			return &lineInfo{t: t, k: t1, vs: t2}, nil
+	// Helper closure for path values, reports patterns that cannot be used.
+	returnPathVal := func(t lineType, val string) (*lineInfo, error) {
+		li, err := parsePathVal(t, key, val)
+		if err != nil {
+			p.report(ln.pos, SeverityError, CodeInvalidPattern, fmt.Sprintf("%s value %q: %v", key, val, err))
 		}
-		return &lineInfo{t: lIgnore}, nil
+		return li, err
 	}
 
-	// Helper closure for all path tokens (allow/disallow), common behaviour:
-	// - Consume t2 token
-	// - If empty, return unknown line info
-	// - Otherwise, normalize the path (add leading "/" if missing, remove trailing "*")
-	// - Detect if wildcards are present, if so, compile into a regexp
-	// - Return the specified line info
-	returnPathVal := func(t lineType) (*lineInfo, error) {
-		p.popToken()
-		if t2 != "" {
-			if !strings.HasPrefix(t2, "*") && !strings.HasPrefix(t2, "/") {
-				t2 = "/" + t2
-			}
-			t2 = strings.TrimRightFunc(t2, isAsterisk)
-			// From google's spec:
-			// Google, Bing, Yahoo, and Ask support a limited form of
-			// "wildcards" for path values. These are:
-			//   * designates 0 or more instances of any valid character
-			//   $ designates the end of the URL
-			if strings.ContainsAny(t2, "*$") {
-				// Must compile a regexp, this is a pattern.
-				// Escape string before compile.
-				t2 = regexp.QuoteMeta(t2)
-				t2 = strings.Replace(t2, `\*`, `.*`, -1)
-				t2 = strings.Replace(t2, `\$`, `$`, -1)
-				if r, e := regexp.Compile(t2); e != nil {
-					return nil, e
-				} else {
-					return &lineInfo{t: t, k: t1, vr: r}, nil
-				}
-			} else {
-				// Simple string path
-				return &lineInfo{t: t, k: t1, vs: t2}, nil
-			}
+	// Helper closure for all string-based values, common behaviour:
+	// - If empty, return ignore line info
+	// - Otherwise return the specified line info
+	returnStringVal := func(t lineType) (*lineInfo, error) {
+		if val != "" {
+			checkSpace()
+			return &lineInfo{t: t, k: key, vs: val}, nil
 		}
 		return &lineInfo{t: lIgnore}, nil
 	}
 
-	switch strings.ToLower(t1) {
-	case tokEOL:
-		// Don't consume t2 and continue parsing
-		return &lineInfo{t: lIgnore}, nil
-
+	switch strings.ToLower(key) {
 	case "user-agent", "useragent", "usser-agent", "ser-agent":
 		// From google's spec:
 		// Handling of <field> elements with simple errors / typos (eg "useragent"
 		// instead of "user-agent") is undefined and may be interpreted as correct
 		// directives by some user-agents.
 		// The user-agent is non-case-sensitive.
-		t2 = strings.ToLower(t2)
+		val = strings.ToLower(val)
 		return returnStringVal(lUserAgent)
 
 	case "disallow":
@@ -248,12 +293,14 @@ func (p *parser) parseLine() (li *lineInfo, err error) {
 		// When no path is specified, the directive is ignored (so an empty Disallow
 		// CAN be an allow, since allow is the default. The actual result depends
 		// on the other rules in the group).
-		return returnPathVal(lDisallow)
+		checkSpace()
+		return returnPathVal(lDisallow, val)
 
 	case "allow":
 		// From google's spec:
 		// When no path is specified, the directive is ignored.
-		return returnPathVal(lAllow)
+		checkSpace()
+		return returnPathVal(lAllow, val)
 
 	case "host":
 		// Host directive to specify main site mirror
@@ -268,61 +315,158 @@ func (p *parser) parseLine() (li *lineInfo, err error) {
 		// From http://en.wikipedia.org/wiki/Robots_exclusion_standard#Nonstandard_extensions
 		// Several major crawlers support a Crawl-delay parameter, set to the
 		// number of seconds to wait between successive requests to the same server.
-		p.popToken()
+		// Invalid values are not worth failing the whole file, so they are
+		// reported and replaced with no delay.
 		cd := 0.0
 		var e error
-		if cd, e = strconv.ParseFloat(t2, 64); e != nil {
-			//return nil, e
+		if cd, e = strconv.ParseFloat(val, 64); e != nil {
+			p.report(ln.pos, SeverityError, CodeInvalidCrawlDelay, fmt.Sprintf("%s value %q is not a number", key, val))
 			cd = 0.0
 		} else if cd < 0 || math.IsInf(cd, 0) || math.IsNaN(cd) {
-			//return nil, fmt.Errorf("Crawl-delay invalid value '%s'", t2)
+			p.report(ln.pos, SeverityError, CodeInvalidCrawlDelay, fmt.Sprintf("%s value %q is out of range", key, val))
 			cd = 0.0
 		}
-		return &lineInfo{t: lCrawlDelay, k: t1, vf: cd}, nil
-	case "clean-param", "cleanparam", "clean-params":
-		// From https://yandex.ru/support/webmaster/robot-workings/clean-param.html?lang=en
-		p.popToken() // pops t1
-		t3, ok := p.peekToken()
-		if !ok || t3 == tokEOL {
-			return &lineInfo{t: lCleanParam, k: t1, vsc: t2}, nil
-		} else {
-			p.popToken() // pops t2
-			li = &lineInfo{t: lCleanParam, k: t1, vsc: t2}
-			t2 = t3
-			pathVal, err := returnPathVal(lCleanParam)
-			if err != nil {
-				return nil, err
-			}
-
-			if pathVal.vr != nil {
-				li.vr = pathVal.vr
+		return &lineInfo{t: lCrawlDelay, k: key, vf: cd}, nil
+
+	case "request-rate", "requestrate":
+		// Nonstandard, supported by Yandex and Seznam among others:
+		// Request-rate: requests/period, where the period is in seconds
+		// unless it has a unit, as in "1/5", "1/10s" or "30/1m".
+		// Invalid values are reported and dropped, an earlier valid rate of
+		// the group stays.
+		fields := strings.Fields(val)
+		if len(fields) > 1 {
+			p.report(ln.pos, SeverityWarning, CodeExtraFields, fmt.Sprintf("%s value %q has more than a rate", key, val))
+		}
+		li = &lineInfo{t: lRequestRate, k: key}
+		if len(fields) > 0 {
+			if rr, e := parseRequestRate(fields[0]); e != nil {
+				p.report(ln.pos, SeverityError, CodeInvalidRequestRate, fmt.Sprintf("%s value %q: %v", key, val, e))
 			} else {
-				li.vs = pathVal.vs
+				li.rr = rr
 			}
+		}
+		return li, nil
+
+	case "visit-time", "visittime":
+		// Nonstandard, the UTC hours in which the agents may visit:
+		// Visit-time: 0100-0545
+		// A group may have several windows. Invalid values are reported and
+		// dropped, they do not restrict visits.
+		li = &lineInfo{t: lVisitTime, k: key}
+		if tw, e := parseTimeWindow(val); e != nil {
+			p.report(ln.pos, SeverityError, CodeInvalidVisitTime, fmt.Sprintf("%s value %q: %v", key, val, e))
+		} else {
+			li.tw = &tw
+		}
+		return li, nil
 
+	case "clean-param", "cleanparam", "clean-params":
+		// From https://yandex.ru/support/webmaster/robot-workings/clean-param.html?lang=en
+		// Clean-param: p0[&p1&p2&..&pn] [path]
+		fields := strings.Fields(val)
+		if len(fields) == 0 {
+			return &lineInfo{t: lCleanParam, k: key}, nil
+		}
+		li = &lineInfo{t: lCleanParam, k: key, vsc: fields[0]}
+		if len(fields) == 1 {
 			return li, nil
 		}
+		if len(fields) > 2 {
+			p.report(ln.pos, SeverityWarning, CodeExtraFields, fmt.Sprintf("%s value %q has more than a parameter list and a path", key, val))
+		}
+		pathVal, err := returnPathVal(lCleanParam, fields[1])
+		if err != nil {
+			return nil, err
+		}
+		li.vs, li.vr, li.raw = pathVal.vs, pathVal.vr, pathVal.raw
+		return li, nil
+
+	case "content-signal", "content-usage":
+		// Preferences for the use of content, by AI systems in particular:
+		// Content-Signal: search=yes, ai-train=no
+		// Content-Usage: /blog/ train-ai=n
+		directive := ContentSignal
+		if strings.EqualFold(key, ContentUsage) {
+			directive = ContentUsage
+		}
+		ur, err := parseUsage(directive, val, func(msg string) {
+			p.report(ln.pos, SeverityWarning, CodeInvalidUsage, msg)
+		})
+		if err != nil {
+			p.report(ln.pos, SeverityError, CodeInvalidPattern, fmt.Sprintf("%s value %q: %v", key, val, err))
+			return nil, err
+		}
+		return &lineInfo{t: lUsage, k: key, ur: ur}, nil
 	}
 
-	// Consume t2 token
-	p.popToken()
-	return &lineInfo{t: lUnknown, k: t1}, nil
+	dir := &Directive{Name: strings.ToLower(key), Key: key, Value: val, Line: ln.pos.Line}
+	if ext := p.exts.lookup(key); ext != nil {
+		dir.Name = ext.Name
+		var e error
+		if dir.Parsed, e = ext.parse(val); e != nil {
+			p.report(ln.pos, SeverityError, CodeInvalidExtension, fmt.Sprintf("%s value %q: %v", key, val, e))
+			return &lineInfo{t: lIgnore}, nil
+		}
+		return &lineInfo{t: lExtension, k: key, ext: ext, dir: dir}, nil
+	}
+
+	p.report(ln.pos, SeverityInfo, CodeUnknownDirective, fmt.Sprintf("unknown directive %q", key))
+	return &lineInfo{t: lUnknown, k: key, dir: dir}, nil
 }
 
-func (p *parser) popToken() (tok string, ok bool) {
-	tok, ok = p.peekToken()
-	if !ok {
-		return
+// parsePathVal parses path values (allow/disallow), common behaviour:
+//   - If empty, return line info without a path
+//   - Otherwise, normalize the path (add leading "/" if missing, percent-encode
+//     as described in normalizePath, remove trailing "*")
+//   - Detect if wildcards are present, if so, compile into a regexp
+//   - Return the specified line info
+func parsePathVal(t lineType, key, val string) (*lineInfo, error) {
+	if val == "" {
+		return &lineInfo{t: t, k: key}, nil
+	}
+	if !strings.HasPrefix(val, "*") && !strings.HasPrefix(val, "/") {
+		val = "/" + val
+	}
+	val = normalizePath(val)
+	raw := val
+	val = strings.TrimRightFunc(val, isAsterisk)
+	// From google's spec:
+	// Google, Bing, Yahoo, and Ask support a limited form of
+	// "wildcards" for path values. These are:
+	//   * designates 0 or more instances of any valid character
+	//   $ designates the end of the URL
+	if strings.ContainsAny(val, "*$") {
+		// Must compile a regexp, this is a pattern.
+		// Escape string before compile.
+		val = regexp.QuoteMeta(val)
+		val = strings.Replace(val, `\*`, `.*`, -1)
+		val = strings.Replace(val, `\$`, `$`, -1)
+		r, e := regexp.Compile(val)
+		if e != nil {
+			return nil, e
+		}
+		return &lineInfo{t: t, k: key, vr: r, raw: raw}, nil
+	}
+	// Simple string path
+	return &lineInfo{t: t, k: key, vs: val, raw: raw}, nil
+}
+
+func (p *parser) popLine() (ln line, ok bool) {
+	if p.pos >= len(p.lines) {
+		return line{}, false
 	}
 	p.pos++
-	return tok, true
+	return p.lines[p.pos-1], true
 }
 
-func (p *parser) peekToken() (tok string, ok bool) {
-	if p.pos >= len(p.tokens) {
-		return "", false
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
 	}
-	return p.tokens[p.pos], true
+	return false
 }
 
 func isAsterisk(r rune) bool {
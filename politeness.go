@@ -0,0 +1,151 @@
+package robotstxt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// minPrune is the number of origins a Politeness or Client tracks before it
+// starts to forget origins it no longer needs.
+const minPrune = 1024
+
+// Politeness spaces requests to each origin by the EffectiveDelay of the
+// agent's group, from Crawl-delay or Request-rate.
+// Requests are given slots in the order Wait is called, one delay apart.
+//
+// The zero value is not usable, create one with NewPoliteness. A Politeness
+// is safe for concurrent use.
+type Politeness struct {
+	Client *Client
+
+	// Default is the delay for groups without Crawl-delay or Request-rate,
+	// zero for none.
+	Default time.Duration
+
+	// Min and Max clamp the delay, Max has no effect if zero.
+	Min time.Duration
+	Max time.Duration
+
+	clock   clock
+	mu      sync.Mutex
+	next    map[string]time.Time // Earliest time of the next request, by origin
+	pruneAt int                  // Size of next that triggers pruning
+}
+
+// clock tells time and waits, tests replace it.
+type clock interface {
+	Now() time.Time
+	// Sleep waits for d or until ctx is done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewPoliteness returns a Politeness that gets robots.txt files from c, or
+// from a new Client if c is nil.
+func NewPoliteness(c *Client) *Politeness {
+	if c == nil {
+		c = NewClient(nil)
+	}
+	return &Politeness{
+		Client:  c,
+		clock:   realClock{},
+		next:    make(map[string]time.Time),
+		pruneAt: minPrune,
+	}
+}
+
+// Wait blocks until agent may request pageURL: one delay after the previous
+// request to the same origin that was let through. It returns early with
+// the error of ctx, or with the error of fetching robots.txt.
+func (p *Politeness) Wait(ctx context.Context, pageURL, agent string) error {
+	u, err := RobotsURL(pageURL)
+	if err != nil {
+		return err
+	}
+	r, err := p.Client.Get(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+
+	key := origin(u)
+	delay := p.delay(r.FindGroup(agent).EffectiveDelay())
+	now, at := p.reserve(key, delay)
+	if !at.After(now) {
+		return nil
+	}
+	if err := p.clock.Sleep(ctx, at.Sub(now)); err != nil {
+		p.release(key, at, delay)
+		return err
+	}
+	return nil
+}
+
+// delay returns the delay to use for a group's effective delay.
+func (p *Politeness) delay(effective time.Duration) time.Duration {
+	d := effective
+	if d <= 0 {
+		d = p.Default
+	}
+	if d < p.Min {
+		d = p.Min
+	}
+	if p.Max > 0 && d > p.Max {
+		d = p.Max
+	}
+	return d
+}
+
+// reserve returns the current time and the time of the next free slot of
+// the origin key, which is taken.
+func (p *Politeness) reserve(key string, delay time.Duration) (now, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now = p.clock.Now()
+	at = now
+	if next, ok := p.next[key]; ok && next.After(now) {
+		at = next
+	}
+	p.next[key] = at.Add(delay)
+	if len(p.next) >= p.pruneAt {
+		p.prune(now)
+	}
+	return now, at
+}
+
+// release gives the slot at back, if no later slot was taken since.
+func (p *Politeness) release(key string, at time.Time, delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.next[key].Equal(at.Add(delay)) {
+		p.next[key] = at
+	}
+}
+
+// prune forgets origins that may be requested right away. It runs when the
+// number of origins doubles, so its cost is spread over reservations.
+func (p *Politeness) prune(now time.Time) {
+	for key, next := range p.next {
+		if !next.After(now) {
+			delete(p.next, key)
+		}
+	}
+	p.pruneAt = 2 * len(p.next)
+	if p.pruneAt < minPrune {
+		p.pruneAt = minPrune
+	}
+}
@@ -0,0 +1,224 @@
+package robotstxt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock for tests, sleepers wake up when it is advanced.
+type fakeClock struct {
+	mu       sync.Mutex
+	t        time.Time
+	sleepers []fakeSleeper
+}
+
+type fakeSleeper struct {
+	at   time.Time
+	wake chan struct{}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.t
+}
+
+func (f *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	f.mu.Lock()
+	s := fakeSleeper{at: f.t.Add(d), wake: make(chan struct{})}
+	f.sleepers = append(f.sleepers, s)
+	f.mu.Unlock()
+	select {
+	case <-s.wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *fakeClock) Add(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.t = f.t.Add(d)
+	sleeping := f.sleepers[:0]
+	for _, s := range f.sleepers {
+		if s.at.After(f.t) {
+			sleeping = append(sleeping, s)
+		} else {
+			close(s.wake)
+		}
+	}
+	f.sleepers = sleeping
+}
+
+// waitSleepers waits until n goroutines sleep.
+func (f *fakeClock) waitSleepers(t *testing.T, n int) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		f.mu.Lock()
+		sleeping := len(f.sleepers)
+		f.mu.Unlock()
+		if sleeping == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d sleepers", n)
+}
+
+const robotsDelays = `User-agent: *
+Crawl-delay: 2
+
+User-agent: slowbot
+Crawl-delay: 60
+
+User-agent: fastbot
+Disallow: /private
+`
+
+func newTestPoliteness(t *testing.T) (*Politeness, string, *fakeClock) {
+	c, ts, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, robotsDelays)
+	})
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	p := NewPoliteness(c)
+	p.clock = clock
+	return p, ts.URL, clock
+}
+
+func TestPolitenessWait(t *testing.T) {
+	t.Parallel()
+	p, base, clock := newTestPoliteness(t)
+	ctx := context.Background()
+
+	require.NoError(t, p.Wait(ctx, base+"/a", "bot"))
+	done := make(chan error)
+	go func() { done <- p.Wait(ctx, base+"/b", "bot") }()
+	clock.waitSleepers(t, 1)
+	clock.Add(time.Second)
+	select {
+	case <-done:
+		t.Fatal("Wait returned before Crawl-delay")
+	default:
+	}
+	clock.Add(time.Second)
+	assert.NoError(t, <-done)
+
+	// The slot after the last request is free again after the delay.
+	clock.Add(2 * time.Second)
+	require.NoError(t, p.Wait(ctx, base+"/c", "bot"))
+}
+
+func TestPolitenessDelay(t *testing.T) {
+	t.Parallel()
+	p, base, _ := newTestPoliteness(t)
+	ctx := context.Background()
+	cases := []struct {
+		agent    string
+		min, max time.Duration
+		def      time.Duration
+		expected time.Duration
+	}{
+		{"bot", 0, 0, 0, 2 * time.Second},
+		{"slowbot", 0, 0, 0, time.Minute},
+		{"slowbot", 0, 10 * time.Second, 0, 10 * time.Second},
+		{"bot", 5 * time.Second, 0, 0, 5 * time.Second},
+		{"fastbot", 0, 0, 0, 0},
+		{"fastbot", 0, 0, time.Second, time.Second},
+		{"fastbot", 3 * time.Second, 0, time.Second, 3 * time.Second},
+	}
+	for i, c := range cases {
+		p.Min, p.Max, p.Default = c.min, c.max, c.def
+		key := "case" + strconv.Itoa(i)
+		r, err := p.Client.Get(ctx, base)
+		require.NoError(t, err)
+		now, _ := p.reserve(key, p.delay(r.FindGroup(c.agent).EffectiveDelay()))
+		_, at := p.reserve(key, 0)
+		assert.Equal(t, c.expected, at.Sub(now), "case %d", i)
+	}
+}
+
+func TestPolitenessOrigins(t *testing.T) {
+	t.Parallel()
+	p, base, _ := newTestPoliteness(t)
+	_, ts, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, robotsDelays)
+	})
+	ctx := context.Background()
+
+	// Origins do not wait for each other.
+	require.NoError(t, p.Wait(ctx, base, "slowbot"))
+	require.NoError(t, p.Wait(ctx, ts.URL, "slowbot"))
+
+	assert.Error(t, p.Wait(ctx, "ftp://example.com/", "bot"))
+}
+
+func TestPolitenessCancel(t *testing.T) {
+	t.Parallel()
+	p, base, clock := newTestPoliteness(t)
+	require.NoError(t, p.Wait(context.Background(), base, "slowbot"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Wait(ctx, base, "slowbot") }()
+	clock.waitSleepers(t, 1)
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+
+	// The slot of the canceled request is given back.
+	clock.Add(time.Minute)
+	require.NoError(t, p.Wait(context.Background(), base, "slowbot"))
+}
+
+func TestPolitenessConcurrent(t *testing.T) {
+	t.Parallel()
+	p, _, _ := newTestPoliteness(t)
+	const n = 100
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		got = make(map[time.Time]bool)
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, at := p.reserve("origin", time.Second)
+			mu.Lock()
+			got[at] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	start := p.clock.Now()
+	for i := 0; i < n; i++ {
+		assert.True(t, got[start.Add(time.Duration(i)*time.Second)], "slot %d", i)
+	}
+}
+
+func TestPolitenessPrune(t *testing.T) {
+	t.Parallel()
+	p, _, clock := newTestPoliteness(t)
+	for i := 0; i < minPrune-1; i++ {
+		p.reserve("origin"+strconv.Itoa(i), time.Second)
+	}
+	p.reserve("slow", time.Hour)
+	assert.Len(t, p.next, minPrune)
+
+	clock.Add(time.Minute)
+	p.reserve("new", time.Second)
+	for i := 0; i < minPrune; i++ {
+		p.reserve("more"+strconv.Itoa(i), time.Second)
+	}
+	// Expired origins were forgotten, the others are kept.
+	assert.Len(t, p.next, minPrune+2)
+	assert.Contains(t, p.next, "slow")
+}
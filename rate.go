@@ -0,0 +1,159 @@
+package robotstxt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestRate is the value of a Request-rate line: at most Requests requests
+// per Period. The zero value means the group has no Request-rate.
+type RequestRate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Interval returns the average time between requests, zero if r is zero.
+func (r RequestRate) Interval() time.Duration {
+	if r.Requests <= 0 {
+		return 0
+	}
+	return r.Period / time.Duration(r.Requests)
+}
+
+// String returns r in robots.txt form, as in "1/5s".
+func (r RequestRate) String() string {
+	return strconv.Itoa(r.Requests) + "/" + strconv.FormatFloat(r.Period.Seconds(), 'f', -1, 64) + "s"
+}
+
+// rateUnits are the units a Request-rate period may have, seconds if none.
+var rateUnits = map[string]time.Duration{
+	"":  time.Second,
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// parseRequestRate parses "requests/period" with an optional unit of the
+// period, as in "1/5", "1/10s" or "30/1m".
+func parseRequestRate(val string) (RequestRate, error) {
+	i := strings.IndexByte(val, '/')
+	if i < 0 {
+		return RequestRate{}, errors.New("no \"/\" between requests and period")
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(val[:i]))
+	if err != nil || n <= 0 {
+		return RequestRate{}, errors.New("number of requests is not a positive integer")
+	}
+	period := strings.ToLower(strings.TrimSpace(val[i+1:]))
+	numEnd := strings.LastIndexAny(period, "0123456789.") + 1
+	unit, ok := rateUnits[strings.TrimSpace(period[numEnd:])]
+	if !ok {
+		return RequestRate{}, errors.New("unknown unit of period")
+	}
+	d, err := strconv.ParseFloat(period[:numEnd], 64)
+	if err != nil || d <= 0 || d*float64(unit) > float64(1<<62) {
+		return RequestRate{}, errors.New("period is not a positive number")
+	}
+	return RequestRate{Requests: n, Period: time.Duration(d * float64(unit))}, nil
+}
+
+// EffectiveDelay returns the time to wait between requests of the group's
+// agents: the stricter of Crawl-delay and Request-rate.
+func (g *Group) EffectiveDelay() time.Duration {
+	d := g.RequestRate.Interval()
+	if g.CrawlDelay > d {
+		d = g.CrawlDelay
+	}
+	return d
+}
+
+// Limiter returns a token bucket that lets requests through one
+// EffectiveDelay of the group apart, without bursts. "Request-rate: 30/1m"
+// gives a request every 2 seconds, not 30 at once. To allow bursts, use
+// NewLimiter(g.EffectiveDelay(), g.RequestRate.Requests).
+func (g *Group) Limiter() *Limiter {
+	return NewLimiter(g.EffectiveDelay(), 1)
+}
+
+// Limiter is a token bucket: it holds up to burst tokens, one is added every
+// interval, and every request takes one. It is safe for concurrent use.
+type Limiter struct {
+	interval time.Duration
+	burst    float64
+	clock    clock
+	mu       sync.Mutex
+	tokens   float64   // Tokens at last, negative if requests wait
+	last     time.Time // Time tokens was updated
+}
+
+// NewLimiter returns a full Limiter that lets a request through every
+// interval, with bursts of up to burst requests. With a zero interval every
+// request goes through at once.
+func NewLimiter(interval time.Duration, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{interval: interval, burst: float64(burst), tokens: float64(burst), clock: realClock{}}
+}
+
+// Interval returns the time between requests the limiter lets through.
+func (l *Limiter) Interval() time.Duration {
+	return l.interval
+}
+
+// Allow takes a token if there is one and reports whether it did.
+func (l *Limiter) Allow() bool {
+	if l.interval <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(l.clock.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait takes a token, waiting for one if needed. If ctx is done first, the
+// token is given back and the error of ctx returned.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	l.advance(l.clock.Now())
+	l.tokens--
+	wait := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if err := l.clock.Sleep(ctx, wait); err != nil {
+		l.mu.Lock()
+		l.advance(l.clock.Now())
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// advance adds the tokens accrued since l.last. l.mu must be held.
+func (l *Limiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		if !l.last.IsZero() {
+			l.tokens += float64(elapsed) / float64(l.interval)
+		}
+		l.last = now
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
@@ -0,0 +1,149 @@
+package robotstxt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequestRate(t *testing.T) {
+	t.Parallel()
+	valid := map[string]RequestRate{
+		"1/5":     {1, 5 * time.Second},
+		"1/10s":   {1, 10 * time.Second},
+		"3/20S":   {3, 20 * time.Second},
+		"30/1m":   {30, time.Minute},
+		"100/1h":  {100, time.Hour},
+		"1000/1d": {1000, 24 * time.Hour},
+		"1/0.5":   {1, 500 * time.Millisecond},
+		"2 / 5 s": {2, 5 * time.Second},
+	}
+	for val, expect := range valid {
+		rr, err := parseRequestRate(val)
+		if assert.NoError(t, err, val) {
+			assert.Equal(t, expect, rr, val)
+		}
+	}
+	for _, val := range []string{"", "5", "0/5", "-1/5", "x/5", "1/", "1/0", "1/-5", "1/5w", "1/s", "1/1e30d"} {
+		_, err := parseRequestRate(val)
+		assert.Error(t, err, val)
+	}
+}
+
+func TestRequestRate(t *testing.T) {
+	t.Parallel()
+	r, diags, err := Parse([]byte(`User-agent: a
+Request-rate: 1/10s
+Crawl-delay: 2
+
+User-agent: b
+Request-rate: 3/6s
+Crawl-delay: 5
+
+User-agent: c
+Requestrate: 1/5 0600-0845
+
+User-agent: d
+Request-rate: often
+Disallow: /private
+
+User-agent: e
+Request-rate: 1/20
+Request-rate: sometimes
+`))
+	require.NoError(t, err)
+
+	a := r.FindGroup("a")
+	assert.Equal(t, RequestRate{1, 10 * time.Second}, a.RequestRate)
+	assert.Equal(t, 10*time.Second, a.EffectiveDelay())
+	b := r.FindGroup("b")
+	assert.Equal(t, 2*time.Second, b.RequestRate.Interval())
+	assert.Equal(t, 5*time.Second, b.EffectiveDelay())
+	assert.Equal(t, RequestRate{1, 5 * time.Second}, r.FindGroup("c").RequestRate)
+	assert.Equal(t, RequestRate{}, r.FindGroup("d").RequestRate)
+	assert.Equal(t, time.Duration(0), r.FindGroup("d").EffectiveDelay())
+	assert.False(t, r.TestAgent("/private", "d"))
+	// Invalid values do not replace valid ones.
+	assert.Equal(t, RequestRate{1, 20 * time.Second}, r.FindGroup("e").RequestRate)
+
+	require.Len(t, diags, 3)
+	assert.Equal(t, CodeExtraFields, diags[0].Code)
+	assert.Equal(t, 10, diags[0].Line)
+	assert.Equal(t, CodeInvalidRequestRate, diags[1].Code)
+	assert.Equal(t, 13, diags[1].Line)
+	assert.Equal(t, CodeInvalidRequestRate, diags[2].Code)
+	assert.Equal(t, 18, diags[2].Line)
+
+	assert.Equal(t, "User-agent: a\nCrawl-delay: 2\nRequest-rate: 1/10s\n", a.String())
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	r2 := &RobotsData{}
+	require.NoError(t, json.Unmarshal(data, r2))
+	assert.Equal(t, a.RequestRate, r2.FindGroup("a").RequestRate)
+
+	built, err := NewBuilder().Group("*").RequestRate(1, 5*time.Second).Build()
+	require.NoError(t, err)
+	assert.Equal(t, RequestRate{1, 5 * time.Second}, built.FindGroup("x").RequestRate)
+	_, err = NewBuilder().Group("*").RequestRate(0, time.Second).Build()
+	assert.Error(t, err)
+}
+
+func TestGroupLimiter(t *testing.T) {
+	t.Parallel()
+	l := (&Group{RequestRate: RequestRate{3, 6 * time.Second}, CrawlDelay: time.Second}).Limiter()
+	assert.Equal(t, 2*time.Second, l.Interval())
+	assert.Equal(t, 1.0, l.burst)
+	l = (&Group{RequestRate: RequestRate{3, 6 * time.Second}, CrawlDelay: 5 * time.Second}).Limiter()
+	assert.Equal(t, 5*time.Second, l.Interval())
+	assert.Equal(t, 1.0, l.burst)
+
+	l = (&Group{}).Limiter()
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow())
+		assert.NoError(t, l.Wait(context.Background()))
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLimiter(2*time.Second, 2)
+	l.clock = clock
+	ctx := context.Background()
+
+	// A full bucket lets a burst through.
+	assert.True(t, l.Allow())
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+	clock.Add(time.Second)
+	assert.False(t, l.Allow())
+	clock.Add(time.Second)
+	assert.True(t, l.Allow())
+
+	// Waiters queue up one interval apart.
+	done := make(chan error, 2)
+	go func() { done <- l.Wait(ctx) }()
+	clock.waitSleepers(t, 1)
+	go func() { done <- l.Wait(ctx) }()
+	clock.waitSleepers(t, 2)
+	clock.Add(2 * time.Second)
+	assert.NoError(t, <-done)
+	clock.waitSleepers(t, 1)
+	clock.Add(2 * time.Second)
+	assert.NoError(t, <-done)
+
+	// A canceled wait gives its token back.
+	cctx, cancel := context.WithCancel(ctx)
+	go func() { done <- l.Wait(cctx) }()
+	clock.waitSleepers(t, 1)
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+	clock.Add(2 * time.Second)
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+}
@@ -0,0 +1,104 @@
+package robotstxt
+
+import (
+	"io"
+)
+
+// DefaultMaxSize is the number of bytes of a robots.txt file parsed by
+// default. From RFC 9309 section 2.5:
+// Crawlers SHOULD impose a parsing limit to protect their systems; see
+// Section 3. The parsing limit MUST be at least 500 kibibytes [KiB].
+const DefaultMaxSize = 500 << 10
+
+// readChunkSize is the size of the chunks read by FromReader. Chunks grow
+// while a single line does not fit.
+const readChunkSize = 32 << 10
+
+// ParseOptions tune FromReader. The zero value gives the defaults.
+type ParseOptions struct {
+	// MaxSize is the number of bytes read at most, DefaultMaxSize if zero
+	// and no limit if negative. Content past the limit is not read, and the
+	// line it cuts is dropped.
+	MaxSize int64
+
+	// Extensions are parsed in addition to the directives known to this
+	// package, if not nil.
+	Extensions *Registry
+}
+
+func (o ParseOptions) maxSize() int64 {
+	if o.MaxSize == 0 {
+		return DefaultMaxSize
+	}
+	return o.MaxSize
+}
+
+// FromReader parses robots.txt content from r, which is read in chunks up to
+// opts.MaxSize bytes. RobotsData.Truncated tells whether r had more.
+func FromReader(r io.Reader, opts ParseOptions) (*RobotsData, error) {
+	robots, _, err := parseReader("reader", r, opts)
+	return robots, err
+}
+
+func parseReader(srcname string, rd io.Reader, opts ParseOptions) (r *RobotsData, diags []Diagnostic, err error) {
+	limit := opts.maxSize()
+	if limit >= 0 {
+		// One more byte tells whether there is more than the limit.
+		rd = io.LimitReader(rd, limit+1)
+	}
+
+	var (
+		sc        = newByteScanner(srcname, true)
+		lines     []line
+		buf       = make([]byte, 0, readChunkSize)
+		read      int64
+		truncated bool
+	)
+	for {
+		if len(buf) == cap(buf) {
+			grown := make([]byte, len(buf), 2*cap(buf))
+			copy(grown, buf)
+			buf = grown
+		}
+		// Pending bytes have no line break, but for a "\r" at their end.
+		from := len(buf) - 1
+		if from < 0 {
+			from = 0
+		}
+		n, rerr := rd.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		read += int64(n)
+		if limit >= 0 && read > limit {
+			truncated = true
+			buf = buf[:len(buf)-int(read-limit)]
+			buf = buf[:lineEnd(buf, true)]
+			rerr = io.EOF
+		}
+		end := rerr == io.EOF
+		if rerr != nil && !end {
+			return nil, nil, rerr
+		}
+
+		// Only whole lines are scanned, the rest waits for the next chunk.
+		cut := len(buf)
+		if !end {
+			if cut = lineEnd(buf[from:], false); cut > 0 {
+				cut += from
+			}
+		}
+		if cut > 0 || end {
+			sc.feed(buf[:cut], end)
+			lines = append(lines, sc.scanAll()...)
+			buf = append(buf[:0], buf[cut:]...)
+		}
+		if end {
+			break
+		}
+	}
+
+	r, diags, err = parseLines(srcname, lines, sc, opts.Extensions)
+	if r != nil {
+		r.Truncated = truncated
+	}
+	return r, diags, err
+}
@@ -0,0 +1,99 @@
+package robotstxt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromReaderSameAsBytes(t *testing.T) {
+	t.Parallel()
+	inputs := map[string]string{
+		"001":        robotsText001,
+		"google":     robotsGoogle,
+		"html":       robotsTextHTMLAndComments,
+		"vanityfair": robotsTextVanityfair,
+		"empty":      "",
+		"spaces":     " \n\t\n",
+		"crlf":       "User-agent: *\r\nDisallow: /a\r\n\r\nFoo: bar\r\n",
+		"cr":         "User-agent: *\rDisallow: /a\r\rFoo: bar\r",
+		"problems":   "\xef\xbb\xbfUser-agent: *\nCrawl-delay: soon\nDisallow /tmp\n<p>x</p>\nAllow: /caf\xe9",
+		"longline":   "User-agent: *\nDisallow: /" + strings.Repeat("a", 3*readChunkSize) + "\nFoo: bar",
+	}
+	readers := map[string]func(string) io.Reader{
+		"whole":   func(s string) io.Reader { return strings.NewReader(s) },
+		"onebyte": func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+		"half":    func(s string) io.Reader { return iotest.HalfReader(strings.NewReader(s)) },
+		"dataerr": func(s string) io.Reader { return iotest.DataErrReader(strings.NewReader(s)) },
+	}
+	for name, input := range inputs {
+		expect, expectDiags, err := Parse([]byte(input))
+		require.NoError(t, err)
+		for rname, newReader := range readers {
+			r, diags, err := parseReader("bytes", newReader(input), ParseOptions{MaxSize: -1})
+			require.NoError(t, err, "%s %s", name, rname)
+			assert.Equal(t, expect.String(), r.String(), "%s %s", name, rname)
+			assert.Equal(t, expectDiags, diags, "%s %s", name, rname)
+			assert.False(t, r.Truncated)
+		}
+	}
+}
+
+func TestFromReaderMaxSize(t *testing.T) {
+	t.Parallel()
+	const input = "User-agent: *\nDisallow: /a\nDisallow: /b\n"
+
+	r, err := FromReader(strings.NewReader(input), ParseOptions{MaxSize: int64(len(input))})
+	require.NoError(t, err)
+	assert.False(t, r.Truncated)
+	expectAllAgents(t, r, false, "/b")
+
+	// The cut "Disallow: /b" line is dropped, not read as "Disallow: /".
+	for _, size := range []int{len(input) - 1, len(input) - 5, 27} {
+		r, err = FromReader(strings.NewReader(input), ParseOptions{MaxSize: int64(size)})
+		require.NoError(t, err)
+		assert.True(t, r.Truncated, "size %d", size)
+		expectAllAgents(t, r, false, "/a")
+		expectAllAgents(t, r, true, "/b")
+	}
+
+	r, err = FromReader(strings.NewReader(input), ParseOptions{MaxSize: 5})
+	require.NoError(t, err)
+	assert.True(t, r.Truncated)
+	assert.True(t, r.TestAgent("/a", "bot"))
+}
+
+func TestFromReaderDefaultMaxSize(t *testing.T) {
+	t.Parallel()
+	var b bytes.Buffer
+	b.WriteString("User-agent: *\n")
+	for b.Len() <= DefaultMaxSize {
+		b.WriteString("Disallow: /private/path/that/is/long\n")
+	}
+	b.WriteString("Disallow: /after\n")
+
+	r, err := FromResponse(newHttpResponse(200, b.String()))
+	require.NoError(t, err)
+	assert.True(t, r.Truncated)
+	expectAllAgents(t, r, false, "/private/path/that/is/long")
+	expectAllAgents(t, r, true, "/after")
+
+	r, err = FromReader(bytes.NewReader(b.Bytes()), ParseOptions{MaxSize: -1})
+	require.NoError(t, err)
+	assert.False(t, r.Truncated)
+	expectAllAgents(t, r, false, "/after")
+}
+
+func TestFromReaderError(t *testing.T) {
+	t.Parallel()
+	errBroken := errors.New("broken")
+	r, err := FromReader(io.MultiReader(strings.NewReader("User-agent: *\n"), iotest.ErrReader(errBroken)), ParseOptions{})
+	assert.Nil(t, r)
+	assert.Equal(t, errBroken, err)
+}
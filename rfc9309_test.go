@@ -0,0 +1,143 @@
+package robotstxt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test cases from RFC 9309 and its examples:
+// https://www.rfc-editor.org/rfc/rfc9309.html
+
+func TestRFC9309Precedence(t *testing.T) {
+	const robotsCaseRFC9309 = `user-agent: foobot
+allow: /example/page/
+disallow: /example/page/disallowed.gif
+
+user-agent: tie
+allow: /page
+disallow: /page
+
+user-agent: wildcard
+allow: /abc
+disallow: /a*c
+
+user-agent: longer
+disallow: /a*c
+allow: /ab
+
+user-agent: anchor
+disallow: /
+allow: /$
+
+user-agent: star
+disallow: /fish*
+allow: /fish`
+
+	cases := map[string][]string{
+		"foobot": {
+			"/example/page/",
+			"/example/page/index.html",
+			"^/example/page/disallowed.gif",
+			"/example/",
+		},
+		"tie": {
+			"/page",
+			"/page/sub",
+		},
+		"wildcard": {
+			"/abc",
+			"/abcdef",
+			"^/a-c",
+		},
+		"longer": {
+			"^/abc",
+			"/ab",
+			"/abd",
+		},
+		"anchor": {
+			"/",
+			"^/index.html",
+		},
+		"star": {
+			"^/fish",
+			"^/fishheads",
+		},
+	}
+
+	r, err := FromString(robotsCaseRFC9309)
+	require.NoError(t, err)
+	r.SetMatchMode(MatchRFC9309)
+	expectPrecedence(t, r, cases)
+}
+
+func TestRFC9309GooglePrecedence(t *testing.T) {
+	cases := map[string][]string{
+		"a": {"/page", "^/test"},
+		"b": {"/folder/page", "^/folder1", "^/folder.htm"},
+		"c": {"^/page.htm", "/page1.asp"},
+		"d": {"/", "^/index"},
+		"e": {"^/page.htm", "/"},
+	}
+	r, err := FromString(robotsCasePrecedence)
+	require.NoError(t, err)
+	r.SetMatchMode(MatchRFC9309)
+	expectPrecedence(t, r, cases)
+}
+
+func TestRFC9309MatchingUnchanged(t *testing.T) {
+	// Matching itself does not depend on the mode, only precedence does.
+	r, err := FromString(robotsCaseMatching)
+	require.NoError(t, err)
+	r.SetMatchMode(MatchRFC9309)
+	for _, p := range []string{"/filename.php", "/folder/filename.php"} {
+		expectAccess(t, r, false, p, "h")
+	}
+	expectAccess(t, r, true, "/filename.php?parameters", "h")
+	expectAccess(t, r, false, "", "a")
+}
+
+func TestRFC9309LegacyDefault(t *testing.T) {
+	r, err := FromString("user-agent: *\nallow: /abc\ndisallow: /a*c")
+	require.NoError(t, err)
+	assert.Equal(t, MatchLegacy, r.FindGroup("bot").MatchMode)
+	expectAccess(t, r, false, "/abc", "bot")
+
+	r.SetMatchMode(MatchRFC9309)
+	expectAccess(t, r, true, "/abc", "bot")
+}
+
+func TestRFC9309JSON(t *testing.T) {
+	r, err := FromString("user-agent: *\nallow: /abc\ndisallow: /a*c")
+	require.NoError(t, err)
+	r.SetMatchMode(MatchRFC9309)
+
+	buf, err := r.MarshalJSON()
+	require.NoError(t, err)
+	restored := &RobotsData{}
+	require.NoError(t, restored.UnmarshalJSON(buf))
+	assert.Equal(t, MatchRFC9309, restored.FindGroup("bot").MatchMode)
+	expectAccess(t, restored, true, "/abc", "bot")
+}
+
+// expectPrecedence checks paths per agent, a leading "^" means disallowed.
+func expectPrecedence(t *testing.T, r *RobotsData, cases map[string][]string) {
+	for agent, paths := range cases {
+		for _, p := range paths {
+			allow := !strings.HasPrefix(p, "^")
+			expectAccess(t, r, allow, strings.TrimPrefix(p, "^"), agent)
+		}
+	}
+}
+
+func TestRFC9309AnchoredWildcards(t *testing.T) {
+	r, err := FromString("user-agent: *\ndisallow: /fish*.php")
+	require.NoError(t, err)
+	expectAccess(t, r, false, "/sea/fish.php", "bot")
+
+	r.SetMatchMode(MatchRFC9309)
+	expectAccess(t, r, true, "/sea/fish.php", "bot")
+	expectAccess(t, r, false, "/fishheads/catfish.php", "bot")
+}
@@ -1,9 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
-	"net/http"
 	"strings"
 
 	"github.com/temoto/robotstxt"
@@ -24,15 +24,14 @@ func main() {
 		*robotsUrl = "http://" + *robotsUrl
 	}
 
-	response, err := http.Get(*robotsUrl)
-	if err != nil {
-		log.Fatalln("HTTP error:", err)
-	}
-
-	robots, err := robotstxt.FromResponse(response)
+	fetcher := &robotstxt.Fetcher{UserAgent: "robots.txt-check"}
+	robots, err := fetcher.Fetch(context.Background(), *robotsUrl)
 	if err != nil {
 		log.Fatalln("Robots.txt error:", err)
 	}
+	if robots.Unreachable() {
+		log.Println("Robots.txt is unreachable, everything is disallowed")
+	}
 
 	log.Println("Running checks as", *bot)
 	group := robots.FindGroup(*bot)
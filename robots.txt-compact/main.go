@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory of the file store")
+	maxAge := flag.Duration("max-age", robotstxt.DefaultMaxStale, "remove records fetched longer ago")
+	flag.Parse()
+	if *dir == "" {
+		log.Fatalln("Store directory is empty, run with -h to see usage.")
+	}
+
+	store, err := robotstxt.NewFileStore(*dir)
+	if err != nil {
+		log.Fatalln("Store error:", err)
+	}
+	removed, err := store.Compact(time.Now().Add(-*maxAge))
+	if err != nil {
+		log.Fatalln("Compact error:", err)
+	}
+	log.Println("Removed", removed, "files")
+}
@@ -9,9 +9,7 @@ package robotstxt
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -21,10 +19,7 @@ import (
 )
 
 const (
-	AnyGroupId                    = "*"
-	regexToRemoveAllPairTagsHTML  = `<.*?>.*?</.*?>|<.*?>|<!.*?>`
-	regexToRemoveSpaceBeforeColin = `\s+:`
-	regexToRemoveAllComments      = `#.*$`
+	AnyGroupId = "*"
 )
 
 type RobotsData struct {
@@ -32,8 +27,30 @@ type RobotsData struct {
 	groups      map[string]*Group
 	allowAll    bool
 	disallowAll bool
+	unreachable bool
+	stale       bool
+	extensions  []Directive // Global extension lines
+	unknown     []Directive
 	Host        string
 	Sitemaps    []string
+	// Truncated is set when the file was longer than ParseOptions.MaxSize
+	// and only the lines before the limit were parsed.
+	Truncated bool
+	// AgentMatchMode says how agents are matched to groups. Data decoded
+	// from JSON written before it existed uses AgentMatchPrefix, as it did.
+	AgentMatchMode AgentMatchMode
+	// Origin is the URL the file was requested from, before redirects, if
+	// known. The rules apply to its authority: only its scheme, host and
+	// port matter, see TestURL.
+	Origin *url.URL
+
+	// Fetch metadata, set by FromResponse.
+	FinalURL     *url.URL // URL the file was served from, after redirects
+	FetchedAt    time.Time
+	StatusCode   int
+	ETag         string
+	LastModified string // Last-Modified header as sent, for If-Modified-Since
+	CacheControl string
 }
 
 type Group struct {
@@ -41,12 +58,34 @@ type Group struct {
 	cleanParamRules []*cleanParamRule
 	Agent           string
 	CrawlDelay      time.Duration
+	RequestRate     RequestRate
+	VisitTimes      []TimeWindow
+	MatchMode       MatchMode
+	usageRules      []*usageRule
+	extensions      []Directive
 }
 
+// MatchMode selects how Group.Test picks a rule when several rules match.
+type MatchMode int
+
+const (
+	// MatchLegacy ranks plain rules by path length and wildcard rules by the
+	// length of their compiled regexp, as this package always did.
+	MatchLegacy MatchMode = iota
+
+	// MatchRFC9309 follows RFC 9309 section 2.2.2: patterns match from the
+	// start of the path, the matching rule with the longest path pattern,
+	// counted in octets as written, wins, and Allow wins over Disallow when
+	// both are equally long.
+	MatchRFC9309
+)
+
 type rule struct {
 	path    string
 	allow   bool
 	pattern *regexp.Regexp
+	raw     string
+	line    int
 }
 
 // For more information, see https://yandex.ru/support/webmaster/robot-workings/clean-param.html?lang=en
@@ -54,14 +93,40 @@ type cleanParamRule struct {
 	params  []string
 	path    string
 	pattern *regexp.Regexp
+	raw     string
+	line    int
+}
+
+// Rule describes an Allow or Disallow rule of a group, see Group.Rules.
+type Rule struct {
+	// Pattern is the path pattern as written, percent-encoded as described
+	// for Group.Test and with a leading "/" added if it was missing.
+	Pattern  string
+	Allow    bool
+	Wildcard bool // Pattern contains "*"
+	Anchored bool // Pattern ends with "$", so it matches whole paths only
+	Line     int  // Line of the rule in the source, 0 if unknown
+}
+
+// CleanParamRule describes a Clean-param rule of a group, see
+// Group.CleanParamRules.
+type CleanParamRule struct {
+	Params []string
+	// Pattern is the path pattern the rule is limited to, in the same form
+	// as Rule.Pattern, or "" if it applies to all paths.
+	Pattern string
+	Line    int
 }
 
 type ParseError struct {
 	Errs []error
+	// Diagnostics holds everything found while parsing, including the
+	// lines that caused Errs.
+	Diagnostics []Diagnostic
 }
 
-func newParseError(errs []error) *ParseError {
-	return &ParseError{errs}
+func newParseError(errs []error, diags []Diagnostic) *ParseError {
+	return &ParseError{errs, diags}
 }
 
 func (e ParseError) Error() string {
@@ -74,114 +139,112 @@ func (e ParseError) Error() string {
 	return b.String()
 }
 
-var allowAll = &RobotsData{allowAll: true}
-var disallowAll = &RobotsData{disallowAll: true}
 var emptyGroup = &Group{}
 
+// FromStatusAndBytes interprets a robots.txt response like Google did when
+// this function was written: 2xx parse the body, 4xx allow all, 5xx disallow
+// all and other statuses are an error. See FromStatusAndBytesWithPolicy for
+// other interpretations.
 func FromStatusAndBytes(statusCode int, body []byte) (*RobotsData, error) {
-	switch {
-	case statusCode >= 200 && statusCode < 300:
-		return FromBytes(body)
-
-	// From https://developers.google.com/webmasters/control-crawl-index/docs/robots_txt
-	//
-	// Google treats all 4xx errors in the same way and assumes that no valid
-	// robots.txt file exists. It is assumed that there are no restrictions.
-	// This is a "full allow" for crawling. Note: this includes 401
-	// "Unauthorized" and 403 "Forbidden" HTTP result codes.
-	case statusCode >= 400 && statusCode < 500:
-		return allowAll, nil
-
-	// From Google's spec:
-	// Server errors (5xx) are seen as temporary errors that result in a "full
-	// disallow" of crawling.
-	case statusCode >= 500 && statusCode < 600:
-		return disallowAll, nil
-	}
-
-	return nil, errors.New("Unexpected status: " + strconv.Itoa(statusCode))
+	return FromStatusAndBytesWithPolicy(statusCode, body, defaultStatusPolicy)
 }
 
 func FromStatusAndString(statusCode int, body string) (*RobotsData, error) {
 	return FromStatusAndBytes(statusCode, []byte(body))
 }
 
+// FromResponse parses the robots.txt response res, interpreting its status
+// like FromStatusAndBytes. The body of successful responses is read up to
+// DefaultMaxSize, see FromReader.
 func FromResponse(res *http.Response) (*RobotsData, error) {
-	if res == nil {
-		// Edge case, if res is nil, return nil data
-		return nil, nil
-	}
-	buf, e := ioutil.ReadAll(res.Body)
-	if e != nil {
-		return nil, e
+	return FromResponseWithPolicy(res, defaultStatusPolicy)
+}
+
+// setResponse records where and when r was fetched. Origin is the URL of
+// the first request, RFC 9309 section 2.3.1.2 applies the rules to the
+// authority that was requested, not to the one redirected to.
+func (r *RobotsData) setResponse(res *http.Response, now time.Time) {
+	if req := res.Request; req != nil && req.URL != nil {
+		final := *req.URL
+		r.FinalURL = &final
+		for req.Response != nil && req.Response.Request != nil && req.Response.Request.URL != nil {
+			req = req.Response.Request
+		}
+		origin := *req.URL
+		r.Origin = &origin
+	}
+	r.FetchedAt = now
+	r.StatusCode = res.StatusCode
+	r.ETag = res.Header.Get("ETag")
+	r.LastModified = res.Header.Get("Last-Modified")
+	r.CacheControl = res.Header.Get("Cache-Control")
+}
+
+// MaxAge returns how long the file may be used after FetchedAt according to
+// its Cache-Control header. ok is false if the header does not say; no-cache
+// and no-store give a zero age.
+func (r *RobotsData) MaxAge() (age time.Duration, ok bool) {
+	for _, d := range strings.Split(r.CacheControl, ",") {
+		name, value := strings.TrimSpace(d), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), `"`)
+		}
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0, true
+		case "max-age":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil && secs >= 0 {
+				age, ok = time.Duration(secs)*time.Second, true
+			}
+		}
 	}
-	return FromStatusAndBytes(res.StatusCode, buf)
-}
-
-// This method uses a regular expresion to remove HTML.
-// must be of the form <tag> txt <\tag> ie well formed.
-func stripHtmlRegex(s string) string {
-	r1 := regexp.MustCompile(regexToRemoveAllPairTagsHTML)
-	return r1.ReplaceAllString(s, "")
-}
-
-func stripSpaceBeforeColin(s string) string {
-	r1 := regexp.MustCompile(regexToRemoveSpaceBeforeColin)
-	return r1.ReplaceAllString(s, ":")
-}
-
-func stripComments(s string) string {
-	r1 := regexp.MustCompile(regexToRemoveAllComments)
-	return r1.ReplaceAllString(s, "")
+	return age, ok
 }
 
 func FromBytes(body []byte) (r *RobotsData, err error) {
-	var errs []error
+	r, _, err = parse("bytes", body)
+	return r, err
+}
 
+func parse(srcname string, body []byte) (r *RobotsData, diags []Diagnostic, err error) {
 	// special case (probably not worth optimization?)
 	trimmed := bytes.TrimSpace(body)
 	if len(trimmed) == 0 {
-		return allowAll, nil
+		return &RobotsData{allowAll: true}, nil, nil
 	}
 
-	// toss any comments ie anything following a "#"
-	noComments := stripComments(string(trimmed))
-	if len(noComments) == 0 {
-		return allowAll, nil
-	}
+	sc := newByteScanner(srcname, true)
+	// sc.Quiet = !print_errors
+	sc.feed(body, true)
+	return parseLines(srcname, sc.scanAll(), sc, nil)
+}
 
-	// toss any html
-	trimedFromHtml := stripHtmlRegex(noComments)
-	if len(trimedFromHtml) == 0 {
-		return allowAll, nil
-	}
+// parseLines builds RobotsData from scanned lines, with the extensions of
+// exts if not nil. Diagnostics are collected on sc, a quiet scanner is
+// created if it is nil.
+func parseLines(srcname string, lines []line, sc *byteScanner, exts *Registry) (r *RobotsData, diags []Diagnostic, err error) {
+	var errs []error
 
-	// replace " :" with ":"
-	trimedFromSpaceColin := stripSpaceBeforeColin(trimedFromHtml)
-	if len(trimedFromSpaceColin) == 0 {
-		return allowAll, nil
+	if sc == nil {
+		sc = newByteScanner(srcname, true)
 	}
 
-	body = []byte(trimedFromSpaceColin)
-
-	sc := newByteScanner("bytes", true)
-	// sc.Quiet = !print_errors
-	sc.feed(body, true)
-	tokens := sc.scanAll()
-
 	// special case worth optimization
-	if len(tokens) == 0 {
-		return allowAll, nil
+	if len(lines) == 0 {
+		return &RobotsData{allowAll: true}, sc.diags, nil
 	}
 
 	r = &RobotsData{}
-	parser := newParser(tokens)
+	parser := newParser(lines, sc.report)
+	parser.exts = exts
 	r.groups, r.Host, r.Sitemaps, errs = parser.parseAll()
+	r.extensions, r.unknown = parser.globals, parser.unknown
+	diags = sortDiagnostics(sc.diags)
 	if len(errs) > 0 {
-		return nil, newParseError(errs)
+		return nil, diags, newParseError(errs, diags)
 	}
 
-	return r, nil
+	return r, diags, nil
 }
 
 func FromString(body string) (r *RobotsData, err error) {
@@ -231,12 +294,26 @@ func (r *RobotsData) TestDisallowAll() bool {
 // with the most specific user-agent that still matches. All other groups of
 // records are ignored by the crawler. The user-agent is non-case-sensitive.
 // The order of the groups within the robots.txt file is irrelevant.
+//
+// The agent may be a whole User-Agent header, see AgentMatchMode.
 func (r *RobotsData) FindGroup(agent string) (ret *Group) {
 	_, g := r.FindGroupWithGroupId(agent)
 	return g
 }
 
+// FindGroupWithGroupId is like FindGroup and also returns the user-agent of
+// the group, which is matched as the AgentMatchMode of r says.
 func (r *RobotsData) FindGroupWithGroupId(agent string) (groupId string, ret *Group) {
+	if r.AgentMatchMode == AgentMatchToken {
+		if groupId, ret = r.findGroupByToken(agent); ret != nil {
+			return groupId, ret
+		}
+		if ret = r.groups[AnyGroupId]; ret != nil {
+			return AnyGroupId, ret
+		}
+		return AnyGroupId, emptyGroup
+	}
+
 	var prefixLen int
 
 	agent = strings.ToLower(agent)
@@ -265,13 +342,40 @@ func (r *RobotsData) SetGroups(groups map[string]*Group) {
 	r.groups = groups
 }
 
+// SetMatchMode sets the rule precedence mode of every group.
+func (r *RobotsData) SetMatchMode(mode MatchMode) {
+	for _, g := range r.groups {
+		g.MatchMode = mode
+	}
+}
+
 func (r *RobotsData) MarshalJSON() ([]byte, error) {
+	var originURL string
+	if r.Origin != nil {
+		originURL = r.Origin.String()
+	}
+	var finalURL string
+	if r.FinalURL != nil {
+		finalURL = r.FinalURL.String()
+	}
 	return json.Marshal(map[string]interface{}{
-		"allow_all":    r.allowAll,
-		"disallow_all": r.disallowAll,
-		"groups":       r.groups,
-		"host":         r.Host,
-		"sitemaps":     r.Sitemaps,
+		"allow_all":     r.allowAll,
+		"disallow_all":  r.disallowAll,
+		"unreachable":   r.unreachable,
+		"agent_mode":    r.AgentMatchMode,
+		"groups":        r.groups,
+		"host":          r.Host,
+		"sitemaps":      r.Sitemaps,
+		"truncated":     r.Truncated,
+		"origin":        originURL,
+		"final_url":     finalURL,
+		"extensions":    directivesJSON(r.extensions),
+		"unknown":       directivesJSON(r.unknown),
+		"fetched_at":    r.FetchedAt,
+		"status_code":   r.StatusCode,
+		"etag":          r.ETag,
+		"last_modified": r.LastModified,
+		"cache_control": r.CacheControl,
 	})
 }
 
@@ -291,6 +395,16 @@ func (r *RobotsData) UnmarshalJSON(bytes []byte) error {
 		r.disallowAll = disallowAll
 	}
 
+	if unreachable, ok := robotsDataInterface["unreachable"].(bool); ok {
+		r.unreachable = unreachable
+	}
+
+	if agentMode, ok := robotsDataInterface["agent_mode"].(float64); ok {
+		r.AgentMatchMode = AgentMatchMode(agentMode)
+	} else {
+		r.AgentMatchMode = AgentMatchPrefix
+	}
+
 	if groupInterfaces, ok := robotsDataInterface["groups"].(map[string]interface{}); ok {
 
 		r.groups = make(map[string]*Group, len(groupInterfaces))
@@ -311,8 +425,54 @@ func (r *RobotsData) UnmarshalJSON(bytes []byte) error {
 		r.Host = host
 	}
 
-	if sitemaps, ok := robotsDataInterface["sitemaps"].([]string); ok {
-		r.Sitemaps = sitemaps
+	if sitemaps, ok := robotsDataInterface["sitemaps"].([]interface{}); ok {
+		r.Sitemaps = make([]string, 0, len(sitemaps))
+		for _, s := range sitemaps {
+			if s, ok := s.(string); ok {
+				r.Sitemaps = append(r.Sitemaps, s)
+			}
+		}
+	}
+
+	if truncated, ok := robotsDataInterface["truncated"].(bool); ok {
+		r.Truncated = truncated
+	}
+
+	if originURL, ok := robotsDataInterface["origin"].(string); ok && originURL != "" {
+		if r.Origin, err = url.Parse(originURL); err != nil {
+			return err
+		}
+	}
+
+	r.extensions = directivesFromJSON(robotsDataInterface["extensions"])
+	r.unknown = directivesFromJSON(robotsDataInterface["unknown"])
+
+	if finalURL, ok := robotsDataInterface["final_url"].(string); ok && finalURL != "" {
+		if r.FinalURL, err = url.Parse(finalURL); err != nil {
+			return err
+		}
+	}
+
+	if fetchedAt, ok := robotsDataInterface["fetched_at"].(string); ok {
+		if err = r.FetchedAt.UnmarshalText([]byte(fetchedAt)); err != nil {
+			return err
+		}
+	}
+
+	if statusCode, ok := robotsDataInterface["status_code"].(float64); ok {
+		r.StatusCode = int(statusCode)
+	}
+
+	if etag, ok := robotsDataInterface["etag"].(string); ok {
+		r.ETag = etag
+	}
+
+	if lastModified, ok := robotsDataInterface["last_modified"].(string); ok {
+		r.LastModified = lastModified
+	}
+
+	if cacheControl, ok := robotsDataInterface["cache_control"].(string); ok {
+		r.CacheControl = cacheControl
 	}
 
 	return nil
@@ -333,6 +493,44 @@ func groupInterfaceToGroup(groupInterface interface{}, group *Group) error {
 		group.CrawlDelay = time.Duration(int64(crawlDelay))
 	}
 
+	if rate, ok := groupMapInterface["request_rate"].(map[string]interface{}); ok {
+		requests, _ := rate["requests"].(float64)
+		period, _ := rate["period"].(float64)
+		group.RequestRate = RequestRate{Requests: int(requests), Period: time.Duration(int64(period))}
+	}
+
+	if visitTimes, ok := groupMapInterface["visit_times"].([]interface{}); ok {
+		for _, v := range visitTimes {
+			s, _ := v.(string)
+			tw, err := parseTimeWindow(s)
+			if err != nil {
+				return fmt.Errorf("Could not parse visit time %q: %v", s, err)
+			}
+			group.VisitTimes = append(group.VisitTimes, tw)
+		}
+	}
+
+	if usageRules, ok := groupMapInterface["usage_rules"].([]interface{}); ok {
+		for _, v := range usageRules {
+			m, _ := v.(map[string]interface{})
+			directive, _ := m["directive"].(string)
+			value, _ := m["value"].(string)
+			line, _ := m["line"].(float64)
+			u, err := parseUsage(directive, value, func(string) {})
+			if err != nil {
+				return err
+			}
+			u.line = int(line)
+			group.usageRules = append(group.usageRules, u)
+		}
+	}
+
+	group.extensions = directivesFromJSON(groupMapInterface["extensions"])
+
+	if matchMode, ok := groupMapInterface["match_mode"].(float64); ok {
+		group.MatchMode = MatchMode(matchMode)
+	}
+
 	if rulesAr, ok := groupMapInterface["rules"].([]interface{}); ok && len(rulesAr) > 0 {
 
 		group.rules = make([]*rule, 0, len(rulesAr))
@@ -367,6 +565,14 @@ func ruleInterfaceToRule(ruleInterface interface{}, restoredRule *rule) error {
 		restoredRule.path = path
 	}
 
+	if raw, ok := r["raw"].(string); ok {
+		restoredRule.raw = raw
+	}
+
+	if line, ok := r["line"].(float64); ok {
+		restoredRule.line = int(line)
+	}
+
 	if pattern, ok := r["pattern"].(string); ok && len(pattern) > 0 {
 		var err error
 		restoredRule.pattern, err = regexp.Compile(pattern)
@@ -379,8 +585,11 @@ func ruleInterfaceToRule(ruleInterface interface{}, restoredRule *rule) error {
 	return nil
 }
 
+// Test reports whether path may be crawled by the group's agents. The path is
+// compared in normalized form, so "/caf%C3%A9", "/café" and "/caf%c3%a9" are
+// all the same path.
 func (g *Group) Test(path string) bool {
-	if r := g.findRule(path); r != nil {
+	if r := g.findRule(normalizePath(path)); r != nil {
 		return r.allow
 	}
 
@@ -390,7 +599,7 @@ func (g *Group) Test(path string) bool {
 }
 
 func (g *Group) CleanParams(u *url.URL) *url.URL {
-	r := g.findCleanParamRule(u.Path)
+	r := g.findCleanParamRule(normalizePath(u.EscapedPath()))
 	if r == nil {
 		return u
 	}
@@ -408,6 +617,39 @@ func (g *Group) CleanParams(u *url.URL) *url.URL {
 	return u
 }
 
+// Rules returns the Allow and Disallow rules of the group in source order.
+func (g *Group) Rules() []Rule {
+	rules := make([]Rule, 0, len(g.rules))
+	for _, r := range g.rules {
+		rules = append(rules, r.export())
+	}
+	return rules
+}
+
+// CleanParamRules returns the Clean-param rules of the group in source order.
+func (g *Group) CleanParamRules() []CleanParamRule {
+	rules := make([]CleanParamRule, 0, len(g.cleanParamRules))
+	for _, r := range g.cleanParamRules {
+		rules = append(rules, CleanParamRule{
+			Params:  append([]string(nil), r.params...),
+			Pattern: r.text(),
+			Line:    r.line,
+		})
+	}
+	return rules
+}
+
+func (r *rule) export() Rule {
+	p := r.text()
+	return Rule{
+		Pattern:  p,
+		Allow:    r.allow,
+		Wildcard: strings.Contains(p, "*"),
+		Anchored: strings.HasSuffix(p, "$"),
+		Line:     r.line,
+	}
+}
+
 func (g *Group) CleanParamsString(u string) (string, error) {
 	uu, err := url.Parse(u)
 	if err != nil {
@@ -428,6 +670,10 @@ func (g *Group) CleanParamsString(u string) (string, error) {
 // the less specific (shorter) rule. The order of precedence for rules with
 // wildcards is undefined.
 func (g *Group) findRule(path string) (ret *rule) {
+	if g.MatchMode == MatchRFC9309 {
+		return g.findRuleRFC9309(path)
+	}
+
 	var prefixLen int
 
 	for _, r := range g.rules {
@@ -455,6 +701,49 @@ func (g *Group) findRule(path string) (ret *rule) {
 	return
 }
 
+// From RFC 9309:
+// If there is more than one matching rule, the crawler MUST use the most
+// specific match: the match that has the most octets. If an allow rule and a
+// disallow rule are equivalent, then the allow rule SHOULD be used.
+func (g *Group) findRuleRFC9309(path string) (ret *rule) {
+	best := -1
+
+	for _, r := range g.rules {
+		if !r.match(path) {
+			continue
+		}
+		if l := r.length(); l > best || (l == best && r.allow && !ret.allow) {
+			best = l
+			ret = r
+		}
+	}
+	return
+}
+
+// match reports whether the rule matches path from its first octet.
+func (r *rule) match(path string) bool {
+	if r.pattern != nil {
+		// Leftmost match starts at 0 whenever an anchored match exists.
+		loc := r.pattern.FindStringIndex(path)
+		return loc != nil && loc[0] == 0
+	}
+	// "/" is the weakest match possible, it also covers an empty path.
+	return r.path == "/" || strings.HasPrefix(path, r.path)
+}
+
+// length returns the number of octets in the path pattern as written.
+// Rules restored from JSON produced by older versions have no raw pattern,
+// so fall back to what is left of it.
+func (r *rule) length() int {
+	switch {
+	case r.raw != "":
+		return len(r.raw)
+	case r.pattern != nil:
+		return len(r.pattern.String())
+	}
+	return len(r.path)
+}
+
 func (g *Group) findCleanParamRule(path string) (ret *cleanParamRule) {
 	var prefixLen int
 
@@ -487,9 +776,25 @@ func (g *Group) findCleanParamRule(path string) (ret *cleanParamRule) {
 }
 
 func (g *Group) MarshalJSON() ([]byte, error) {
+	visitTimes := make([]string, len(g.VisitTimes))
+	for i, w := range g.VisitTimes {
+		visitTimes[i] = w.String()
+	}
+	usageRules := make([]map[string]interface{}, len(g.usageRules))
+	for i, u := range g.usageRules {
+		usageRules[i] = map[string]interface{}{"directive": u.directive, "value": u.value, "line": u.line}
+	}
 	return json.Marshal(map[string]interface{}{
 		"agent":       g.Agent,
 		"crawl_delay": g.CrawlDelay.Nanoseconds(),
+		"request_rate": map[string]interface{}{
+			"requests": g.RequestRate.Requests,
+			"period":   g.RequestRate.Period.Nanoseconds(),
+		},
+		"visit_times": visitTimes,
+		"usage_rules": usageRules,
+		"extensions":  directivesJSON(g.extensions),
+		"match_mode":  g.MatchMode,
 		"rules":       g.rules,
 	})
 }
@@ -505,5 +810,7 @@ func (r *rule) MarshalJSON() ([]byte, error) {
 		"allow":   r.allow,
 		"path":    r.path,
 		"pattern": pattern,
+		"raw":     r.raw,
+		"line":    r.line,
 	})
 }
@@ -1,8 +1,12 @@
 package robotstxt
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
@@ -43,6 +47,117 @@ func TestStatus(t *testing.T) {
 	}
 }
 
+func TestFromResponseMetadata(t *testing.T) {
+	t.Parallel()
+	res := newHttpResponse(200, "User-agent: *\nDisallow: /private\nSitemap: https://example.com/sitemap.xml")
+	res.Header = http.Header{
+		"Etag":          {`"abc"`},
+		"Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"},
+		"Cache-Control": {"public, max-age=3600"},
+	}
+	req, err := http.NewRequest("GET", "https://www.example.com/robots.txt", nil)
+	require.NoError(t, err)
+	res.Request = req
+
+	before := time.Now()
+	r, err := FromResponse(res)
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.example.com/robots.txt", r.Origin.String())
+	assert.False(t, r.FetchedAt.Before(before))
+	assert.Equal(t, 200, r.StatusCode)
+	assert.Equal(t, `"abc"`, r.ETag)
+	assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", r.LastModified)
+	age, ok := r.MaxAge()
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, age)
+
+	// The origin is a copy, not the request URL itself.
+	req.URL.Host = "other.example.com"
+	assert.Equal(t, "www.example.com", r.Origin.Host)
+
+	buf, err := r.MarshalJSON()
+	require.NoError(t, err)
+	restored := &RobotsData{}
+	require.NoError(t, restored.UnmarshalJSON(buf))
+	assert.Equal(t, r.Origin, restored.Origin)
+	assert.True(t, r.FetchedAt.Equal(restored.FetchedAt))
+	assert.Equal(t, r.StatusCode, restored.StatusCode)
+	assert.Equal(t, r.ETag, restored.ETag)
+	assert.Equal(t, r.LastModified, restored.LastModified)
+	assert.Equal(t, r.CacheControl, restored.CacheControl)
+	assert.Equal(t, r.Sitemaps, restored.Sitemaps)
+	expectAllAgents(t, restored, false, "/private")
+
+	r, err = FromResponse(newHttpResponse(503, ""))
+	require.NoError(t, err)
+	assert.Equal(t, 503, r.StatusCode)
+	assert.Nil(t, r.Origin)
+	r, err = FromStatusAndBytes(503, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.StatusCode)
+}
+
+func TestFromResponseRedirect(t *testing.T) {
+	t.Parallel()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "User-agent: *\nDisallow: /private\n")
+	}))
+	defer target.Close()
+	// Another authority, as with a redirect from the apex to www.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/robots.txt", http.StatusMovedPermanently)
+	}))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/robots.txt")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	r, err := FromResponse(res)
+	require.NoError(t, err)
+	assert.Equal(t, ts.URL+"/robots.txt", r.Origin.String())
+	assert.Equal(t, target.URL+"/robots.txt", r.FinalURL.String())
+
+	// The rules apply to the site that was asked for.
+	ok, err := r.TestURLString(ts.URL+"/public", "FooBot")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = r.TestURLString(ts.URL+"/private", "FooBot")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, err = r.TestURLString(target.URL+"/public", "FooBot")
+	assert.True(t, errors.Is(err, ErrOtherOrigin))
+
+	buf, err := r.MarshalJSON()
+	require.NoError(t, err)
+	restored := &RobotsData{}
+	require.NoError(t, restored.UnmarshalJSON(buf))
+	assert.Equal(t, r.FinalURL, restored.FinalURL)
+}
+
+func TestMaxAge(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		header string
+		age    time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"public", 0, false},
+		{"max-age=60", time.Minute, true},
+		{"Public, MAX-AGE = 86400", 24 * time.Hour, true},
+		{`max-age="120"`, 2 * time.Minute, true},
+		{"max-age=-1", 0, false},
+		{"max-age=soon", 0, false},
+		{"max-age=60, no-cache", 0, true},
+		{"no-store", 0, true},
+	}
+	for _, c := range cases {
+		age, ok := (&RobotsData{CacheControl: c.header}).MaxAge()
+		assert.Equal(t, c.age, age, c.header)
+		assert.Equal(t, c.ok, ok, c.header)
+	}
+}
+
 func TestFromStringDisallowAll(t *testing.T) {
 	r, err := FromString("User-Agent: *\r\nDisallow: /\r\n")
 	require.NoError(t, err)
@@ -85,6 +200,30 @@ Sitemap: https://www.projectengineer.net/sitemap.xml`)
 	expectAllAgents(t, r, true, "/wp-admin/admin-ajax.php")
 }
 
+// Comments are stripped on every line, not only on the last one
+func TestCommentsOnEveryLine(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`User-agent: * # everyone
+Disallow: /private # keep out
+Allow: /private/open#ing
+# Disallow: /
+Disallow: /tmp # last line`)
+	require.NoError(t, err)
+	expectAllAgents(t, r, false, "/private/x")
+	expectAllAgents(t, r, true, "/private/open")
+	expectAllAgents(t, r, true, "/")
+	expectAllAgents(t, r, false, "/tmp")
+}
+
+// Angle brackets inside path values are not HTML
+func TestAngleBracketsInPath(t *testing.T) {
+	t.Parallel()
+	r, err := FromString("User-agent: *\nDisallow: /search/<query>/results")
+	require.NoError(t, err)
+	expectAllAgents(t, r, false, "/search/<query>/results")
+	expectAllAgents(t, r, true, "/search/")
+}
+
 func TestFromString002(t *testing.T) {
 	t.Parallel()
 	r, err := FromString("User-Agent: *\r\nDisallow: /account\r\n")
@@ -266,6 +405,45 @@ Disallow: /`)
 	expectAccess(t, r, false, "/", "yourbot")
 }
 
+// Values are the rest of the line, not just the first word
+func TestRestOfLineValues(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`
+User-agent: Mozilla/5.0 (compatible; FooBot)
+Disallow: /my file
+Disallow: /other # comment
+
+User-agent: *
+Disallow: /`)
+	require.NoError(t, err)
+	g := r.FindGroup("Mozilla/5.0 (compatible; FooBot)")
+	assert.Equal(t, "mozilla/5.0 (compatible; foobot)", g.Agent)
+	assert.False(t, g.Test("/my file"))
+	assert.False(t, g.Test("/my%20file/sub"))
+	assert.True(t, g.Test("/my"))
+	assert.False(t, g.Test("/other"))
+	assert.True(t, g.Test("/"))
+	_, ok := r.groups["(compatible;"]
+	assert.False(t, ok)
+}
+
+func TestParserReports(t *testing.T) {
+	t.Parallel()
+	_, diags, err := Parse([]byte("User-agent: Foo Bot\nDisallow /x\nDisallow: /my file\nSitemap: http://a b\nClean-param: a b c\n"))
+	require.NoError(t, err)
+	var reports []string
+	for _, d := range diags {
+		reports = append(reports, fmt.Sprintf("%d: %s", d.Line, d.Message))
+	}
+	assert.Equal(t, []string{
+		`1: User-agent value "foo bot" contains whitespace`,
+		`2: missing colon after "Disallow"`,
+		`3: Disallow value "/my file" contains whitespace`,
+		`4: Sitemap value "http://a b" contains whitespace`,
+		`5: Clean-param value "a b c" has more than a parameter list and a path`,
+	}, reports)
+}
+
 func TestInvalidEncoding(t *testing.T) {
 	// Invalid UTF-8 encoding should not break parser.
 	_, err := FromString("User-agent: H\xef\xbf\xbdm�h�kki\nDisallow: *")
@@ -310,6 +488,9 @@ Disallow: /`
 	r, err := FromString(robotsText005)
 	require.NoError(t, err)
 	expectAccess(t, r, false, "/path/page1.html", "SomeBot")
+	expectAccess(t, r, false, "/path/page1.html", "Googlebot")
+	r.AgentMatchMode = AgentMatchPrefix
+	expectAccess(t, r, false, "/path/page1.html", "SomeBot")
 	expectAccess(t, r, true, "/path/page1.html", "Googlebot")
 }
 
@@ -418,6 +599,7 @@ disallow: /c`
 
 	expectAccess(t, r, false, "/a", "b")
 	expectAccess(t, r, false, "/b", "b")
+	expectAccess(t, r, false, "/c", "b")
 	expectAccess(t, r, false, "/c", "c")
 
 	expectAccess(t, r, true, "/a", "c")
@@ -425,6 +607,14 @@ disallow: /c`
 	expectAccess(t, r, false, "/c", "c")
 }
 
+func TestGroupingNoDuplicateRules(t *testing.T) {
+	r, err := FromString("user-agent: a\nuser-agent: a\ndisallow: /a\n\nuser-agent: b\nuser-agent: c\ndisallow: /b")
+	require.NoError(t, err)
+	assert.Len(t, r.groups["a"].rules, 1)
+	assert.Len(t, r.groups["b"].rules, 1)
+	assert.Len(t, r.groups["c"].rules, 1)
+}
+
 func TestCleanParam(t *testing.T) {
 	const robotsWithCleanParam = `User-agent: *
 Disallow: /webstat
@@ -459,6 +649,45 @@ Allow: /wp-*.gif
 	}
 }
 
+func TestGroupRules(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`# Rules
+User-agent: a
+User-agent: b
+Disallow: /private
+Allow: private/open*
+Disallow: /*.pdf$
+Disallow:
+Clean-param: ref&utm /forum/*.php
+Clean-param: sid
+`)
+	require.NoError(t, err)
+	expect := []Rule{
+		{Pattern: "/private", Allow: false, Line: 4},
+		{Pattern: "/private/open*", Allow: true, Wildcard: true, Line: 5},
+		{Pattern: "/*.pdf$", Allow: false, Wildcard: true, Anchored: true, Line: 6},
+	}
+	assert.Equal(t, expect, r.FindGroup("a").Rules())
+	assert.Equal(t, expect, r.FindGroup("b").Rules())
+	assert.Equal(t, []CleanParamRule{
+		{Params: []string{"ref", "utm"}, Pattern: "/forum/*.php", Line: 8},
+		{Params: []string{"sid"}, Pattern: "", Line: 9},
+	}, r.FindGroup("a").CleanParamRules())
+
+	assert.Empty(t, r.FindGroup("other").Rules())
+	assert.Empty(t, r.FindGroup("other").CleanParamRules())
+
+	buf, err := r.MarshalJSON()
+	require.NoError(t, err)
+	restored := &RobotsData{}
+	require.NoError(t, restored.UnmarshalJSON(buf))
+	assert.Equal(t, expect, restored.FindGroup("a").Rules())
+
+	// Returned rules are copies.
+	r.FindGroup("a").CleanParamRules()[0].Params[0] = "x"
+	assert.Equal(t, "ref", r.FindGroup("a").CleanParamRules()[0].Params[0])
+}
+
 func BenchmarkParseFromString001(b *testing.B) {
 	input := robotsText001
 	b.ReportAllocs()
@@ -483,6 +712,34 @@ func BenchmarkParseFromString002(b *testing.B) {
 	}
 }
 
+const robotsTextHTMLAndComments = `<!DOCTYPE html>
+<html><head><title>robots</title></head>
+# Generated by a CMS, do not edit
+<style>#wpadminbar {display:none !important;}</style>User-agent: *
+Disallow: /wp-admin/ # admin area
+Allow: /wp-admin/admin-ajax.php # ajax endpoint
+Crawl-delay : 5
+# Search
+Disallow: /?s= # search
+Disallow: /search/<query>/
+
+User-agent: Googlebot # Google
+Disallow: /private/
+Sitemap: https://www.example.com/sitemap.xml # sitemap
+`
+
+func BenchmarkParseFromStringHTMLAndComments(b *testing.B) {
+	input := robotsTextHTMLAndComments
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := FromString(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkParseFromStatus401(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		if _, err := FromStatusAndString(401, ""); err != nil {
@@ -5,22 +5,23 @@ import (
 	"fmt"
 	"go/token"
 	"os"
+	"strings"
 	"sync"
 	"unicode/utf8"
 )
 
 type byteScanner struct {
-	pos           token.Position
-	buf           []byte
-	ErrorCount    int
-	ch            rune
-	Quiet         bool
-	keyTokenFound bool
-	lastChunk     bool
+	pos        token.Position // Position of s.ch
+	off        int            // Offset of the byte after s.ch in buf
+	base       int            // Offset of buf in the whole input
+	buf        []byte
+	diags      []Diagnostic
+	ErrorCount int
+	ch         rune
+	Quiet      bool
+	lastChunk  bool
 }
 
-const tokEOL = "\n"
-
 var WhitespaceChars = []rune{' ', '\t', '\v'}
 var tokBuffers = sync.Pool{New: func() interface{} { return bytes.NewBuffer(make([]byte, 32)) }}
 
@@ -32,11 +33,22 @@ func newByteScanner(srcname string, quiet bool) *byteScanner {
 	}
 }
 
+// feed sets the next chunk of input, end tells whether it is the last one.
+// Every chunk but the last must end with a line break, positions continue
+// from the previous chunk.
 func (s *byteScanner) feed(input []byte, end bool) {
+	// Line is 0 until the first chunk is fed.
+	first := s.pos.Line == 0
+	if first {
+		s.pos.Line = 1
+		s.pos.Column = 1
+	} else {
+		s.base += len(s.buf)
+	}
 	s.buf = input
-	s.pos.Offset = 0
-	s.pos.Line = 1
-	s.pos.Column = 1
+	s.off = 0
+	s.pos.Offset = s.base
+	s.ch = -1
 	s.lastChunk = end
 
 	// Read first char into look-ahead buffer `s.ch`.
@@ -45,89 +57,115 @@ func (s *byteScanner) feed(input []byte, end bool) {
 	}
 
 	// Skip UTF-8 byte order mark
-	if s.ch == 65279 {
+	if first && s.ch == 65279 {
 		s.nextChar()
 		s.pos.Column = 1
 	}
 }
 
+// lineEnd returns the length of the longest prefix of b made of whole lines.
+// A "\r" at the very end may be the first half of "\r\n", so it only ends
+// a line if final is true.
+func lineEnd(b []byte, final bool) int {
+	i := bytes.LastIndexAny(b, "\r\n")
+	if i >= 0 && i == len(b)-1 && b[i] == '\r' && !final {
+		i = bytes.LastIndexAny(b[:i], "\r\n")
+	}
+	return i + 1
+}
+
 func (s *byteScanner) GetPosition() token.Position {
 	return s.pos
 }
 
-func (s *byteScanner) scan() string {
-	// Note Offset > len, not >=, so we can scan last character.
-	if s.lastChunk && s.pos.Offset > len(s.buf) {
-		return ""
-	}
-
-	s.skipSpace()
-
-	if s.ch == -1 {
-		return ""
-	}
-
-	// EOL
-	if s.isEol() {
-		s.keyTokenFound = false
-		// skip subsequent newline chars
-		for s.ch != -1 && s.isEol() {
-			s.nextChar()
-		}
-		// emit newline as separate token
-		return tokEOL
-	}
+// line is a single "key: value" record of a robots.txt file.
+type line struct {
+	key   string
+	value string
+	pos   token.Position // Position of the first character of the key
+}
 
-	// skip comments
-	if s.ch == '#' {
-		s.keyTokenFound = false
-		s.skipUntilEol()
+// scanLine returns the next record, skipping blank lines and comments. The
+// key is the text before the first colon and the value is the rest of the
+// line up to a comment, both trimmed of whitespace.
+func (s *byteScanner) scanLine() (ln line, ok bool) {
+	tagLine := 0
+	for {
+		s.skipSpace()
 		if s.ch == -1 {
-			return ""
+			return line{}, false
+		}
+		if s.isEol() {
+			s.skipUntilEol()
+			continue
+		}
+		// skip comments
+		if s.ch == '#' {
+			s.skipUntilEol()
+			continue
 		}
-		// emit newline as separate token
-		return tokEOL
+		// Servers often answer with an HTML page, or prepend markup to the
+		// file. Skip tags at the start of a line, so that the rest of the
+		// line is still read, while "<" inside values is kept.
+		if s.ch == '<' {
+			if tagLine != s.pos.Line {
+				tagLine = s.pos.Line
+				s.report(s.pos, SeverityWarning, CodeHTML, "skipped HTML markup")
+			}
+			s.skipTag()
+			continue
+		}
+		break
 	}
 
 	// else we found something
+	ln.pos = s.pos
 	tok := tokBuffers.Get().(*bytes.Buffer)
 	defer tokBuffers.Put(tok)
 	tok.Reset()
-	tok.WriteRune(s.ch)
-	s.nextChar()
-	for s.ch != -1 && !s.isSpace() && !s.isEol() {
-		// Do not consider ":" to be a token separator if a first key token
-		// has already been found on this line (avoid cutting an absolute URL
-		// after the "http:")
-		if s.ch == ':' && !s.keyTokenFound {
-			s.nextChar()
-			s.keyTokenFound = true
-			break
-		}
-
+	for s.ch != -1 && !s.isEol() && s.ch != '#' {
 		tok.WriteRune(s.ch)
 		s.nextChar()
 	}
-	return tok.String()
+	if s.ch == '#' {
+		s.skipUntilEol()
+	}
+
+	text := strings.TrimRightFunc(tok.String(), isSpace)
+	if i := strings.IndexByte(text, ':'); i >= 0 {
+		ln.key = strings.TrimRightFunc(text[:i], isSpace)
+		ln.value = strings.TrimFunc(text[i+1:], isSpace)
+	} else if i := strings.IndexFunc(text, isSpace); i >= 0 {
+		// Be lenient and accept whitespace as the separator, as this
+		// package did before it scanned whole lines.
+		ln.key = text[:i]
+		ln.value = strings.TrimLeftFunc(text[i:], isSpace)
+		s.report(ln.pos, SeverityWarning, CodeMissingColon, fmt.Sprintf("missing colon after %q", ln.key))
+	} else {
+		ln.key = text
+		s.report(ln.pos, SeverityWarning, CodeMissingColon, fmt.Sprintf("missing colon after %q", ln.key))
+	}
+	return ln, true
 }
 
-func (s *byteScanner) scanAll() []string {
-	results := make([]string, 0, 64) // random guess of average tokens length
+func (s *byteScanner) scanAll() []line {
+	results := make([]line, 0, 32) // random guess of average lines count
 	for {
-		token := s.scan()
-		if token != "" {
-			results = append(results, token)
-		} else {
+		ln, ok := s.scanLine()
+		if !ok {
 			break
 		}
+		results = append(results, ln)
 	}
 	return results
 }
 
-func (s *byteScanner) error(pos token.Position, msg string) {
+// report records a diagnostic and prints it unless the scanner is quiet.
+func (s *byteScanner) report(pos token.Position, severity Severity, code, msg string) {
 	s.ErrorCount++
+	s.diags = append(s.diags, newDiagnostic(pos, severity, code, msg))
 	if !s.Quiet {
-		fmt.Fprintf(os.Stderr, "robotstxt from %s: %s\n", pos.String(), msg)
+		fmt.Fprintf(os.Stderr, "robotstxt from %s: %s: %s\n", pos.String(), severity, msg)
 	}
 }
 
@@ -136,8 +174,12 @@ func (s *byteScanner) isEol() bool {
 }
 
 func (s *byteScanner) isSpace() bool {
+	return isSpace(s.ch)
+}
+
+func isSpace(ch rune) bool {
 	for _, r := range WhitespaceChars {
-		if s.ch == r {
+		if ch == r {
 			return true
 		}
 	}
@@ -160,26 +202,53 @@ func (s *byteScanner) skipUntilEol() {
 	}
 }
 
+// skipTag skips an HTML tag, "<" up to and including ">", or the rest of the
+// line if the tag is not closed on it. Element content up to another tag on
+// the same line is skipped too, like the CSS in
+// "<style>#bar {display:none}</style>User-agent: *".
+func (s *byteScanner) skipTag() {
+	for s.ch != -1 && !s.isEol() && s.ch != '>' {
+		s.nextChar()
+	}
+	if s.ch != '>' {
+		return
+	}
+	s.nextChar()
+	if s.ch == -1 || s.ch == '<' || s.isEol() {
+		return
+	}
+
+	rest := s.buf[s.off:]
+	if i := bytes.IndexAny(rest, "<\r\n"); i >= 0 && rest[i] == '<' {
+		for s.ch != '<' {
+			s.nextChar()
+		}
+	}
+}
+
 // Reads next Unicode char.
 func (s *byteScanner) nextChar() bool {
-	if s.pos.Offset >= len(s.buf) {
-		s.ch = -1
-		return false
-	}
-	s.pos.Column++
-	if s.ch == '\n' {
+	// Move the position past the current char. A lone "\r" ends a line too.
+	if s.ch == '\n' || s.ch == '\r' && (s.off >= len(s.buf) || s.buf[s.off] != '\n') {
 		s.pos.Line++
 		s.pos.Column = 1
+	} else if s.ch != -1 {
+		s.pos.Column++
+	}
+	s.pos.Offset = s.base + s.off
+
+	if s.off >= len(s.buf) {
+		s.ch = -1
+		return false
 	}
-	r, w := rune(s.buf[s.pos.Offset]), 1
+	r, w := rune(s.buf[s.off]), 1
 	if r >= 0x80 {
-		r, w = utf8.DecodeRune(s.buf[s.pos.Offset:])
+		r, w = utf8.DecodeRune(s.buf[s.off:])
 		if r == utf8.RuneError && w == 1 {
-			s.error(s.pos, "illegal UTF-8 encoding")
+			s.report(s.pos, SeverityWarning, CodeInvalidUTF8, "illegal UTF-8 encoding")
 		}
 	}
-	s.pos.Column++
-	s.pos.Offset += w
+	s.off += w
 	s.ch = r
 	return true
 }
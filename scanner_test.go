@@ -12,26 +12,40 @@ func TestScanner(t *testing.T) {
 
 	type tcase struct {
 		input    string
-		expect   []string
+		expect   [][2]string
 		errCount int
 	}
 	cases := []tcase{
-		{"foo", []string{"foo"}, 0},
-		{"\u2010", []string{"‐"}, 0},
-		{"# comment \r\nSomething: Somewhere\r\n", []string{tokEOL, "Something", "Somewhere", tokEOL}, 0},
-		{"# comment \r\n# more comments\n\nDisallow:\r", []string{tokEOL, tokEOL, "Disallow", tokEOL}, 0},
-		{"\xef\xbb\xbfUser-agent: *\n", []string{"User-agent", "*", tokEOL}, 0},
-		{"\xd9\xd9", []string{"\uFFFD\uFFFD"}, 2},
-		{"User-Agent: Yandex\nClean-Param: abc&dfg /path", []string{"User-Agent", "Yandex", tokEOL, "Clean-Param", "abc&dfg", "/path"}, 0},
+		{"foo", [][2]string{{"foo", ""}}, 1},
+		{"\u2010", [][2]string{{"‐", ""}}, 1},
+		{"# comment \r\nSomething: Somewhere\r\n", [][2]string{{"Something", "Somewhere"}}, 0},
+		{"# comment \r\n# more comments\n\nDisallow:\r", [][2]string{{"Disallow", ""}}, 0},
+		{"\xef\xbb\xbfUser-agent: *\n", [][2]string{{"User-agent", "*"}}, 0},
+		{"\xd9\xd9", [][2]string{{"\uFFFD\uFFFD", ""}}, 3},
+		{"User-Agent: Yandex\nClean-Param: abc&dfg /path", [][2]string{{"User-Agent", "Yandex"}, {"Clean-Param", "abc&dfg /path"}}, 0},
+		{"User-agent: Mozilla/5.0 (compatible; FooBot)\n", [][2]string{{"User-agent", "Mozilla/5.0 (compatible; FooBot)"}}, 0},
+		{"Disallow: /my file # comment\nAllow:/a#b", [][2]string{{"Disallow", "/my file"}, {"Allow", "/a"}}, 0},
+		{"Sitemap: http://example.com/sitemap.xml", [][2]string{{"Sitemap", "http://example.com/sitemap.xml"}}, 0},
+		{"  Crawl-delay \t :  5  \n", [][2]string{{"Crawl-delay", "5"}}, 0},
+		{"Disallow /private\n", [][2]string{{"Disallow", "/private"}}, 1},
+		{"<!DOCTYPE html>\n<html><title></title>\nUser-agent: *", [][2]string{{"User-agent", "*"}}, 2},
+		{"<style>#bar {display:none} </style>User-agent: *", [][2]string{{"User-agent", "*"}}, 1},
+		{"<b>Disallow: /a</b>\n", [][2]string{}, 1},
+		{"Disallow: /search/<query>/\n", [][2]string{{"Disallow", "/search/<query>/"}}, 0},
+		{"<!-- unclosed\nAllow: /", [][2]string{{"Allow", "/"}}, 1},
 	}
 	for i, c := range cases {
 		tag := fmt.Sprintf("test-%d", i)
 		t.Run(tag, func(t *testing.T) {
 			sc := newByteScanner(tag, true)
 			sc.feed([]byte(c.input), true)
-			tokens := sc.scanAll()
+			lines := sc.scanAll()
+			actual := make([][2]string, 0, len(lines))
+			for _, ln := range lines {
+				actual = append(actual, [2]string{ln.key, ln.value})
+			}
 			assert.Equal(t, c.errCount, sc.ErrorCount)
-			assert.Equal(t, c.expect, tokens)
+			assert.Equal(t, c.expect, actual)
 		})
 	}
 }
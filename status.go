@@ -0,0 +1,216 @@
+package robotstxt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StatusAction is what a robots.txt response status means for crawling.
+type StatusAction int
+
+const (
+	// StatusUnexpected makes the constructors fail with an error.
+	StatusUnexpected StatusAction = iota
+	// StatusParse parses the response body.
+	StatusParse
+	// StatusAllowAll allows everything, as if there were no robots.txt.
+	StatusAllowAll
+	// StatusDisallowAll disallows everything.
+	StatusDisallowAll
+	// StatusUnreachable disallows everything, and marks the result as a
+	// temporary failure, see RobotsData.Unreachable.
+	StatusUnreachable
+)
+
+func (a StatusAction) String() string {
+	switch a {
+	case StatusUnexpected:
+		return "unexpected"
+	case StatusParse:
+		return "parse"
+	case StatusAllowAll:
+		return "allow-all"
+	case StatusDisallowAll:
+		return "disallow-all"
+	case StatusUnreachable:
+		return "unreachable"
+	}
+	return fmt.Sprintf("StatusAction(%d)", int(a))
+}
+
+// StatusPolicy maps robots.txt response statuses to actions. Overrides take
+// precedence over the actions for classes of statuses. Statuses outside
+// 200-599 are unexpected unless overridden.
+type StatusPolicy struct {
+	Success     StatusAction // 2xx
+	Redirect    StatusAction // 3xx, seen when redirects were not followed to the end
+	ClientError StatusAction // 4xx
+	ServerError StatusAction // 5xx
+	Overrides   map[int]StatusAction
+
+	// NetworkError is the action of a Fetcher when there is no response at
+	// all, because of DNS, connection or timeout errors. StatusUnexpected
+	// returns the error, StatusParse is not valid here.
+	NetworkError StatusAction
+}
+
+// defaultStatusPolicy is the behaviour of FromStatusAndBytes.
+var defaultStatusPolicy = StatusPolicy{
+	Success: StatusParse,
+
+	// From https://developers.google.com/webmasters/control-crawl-index/docs/robots_txt
+	//
+	// Google treats all 4xx errors in the same way and assumes that no valid
+	// robots.txt file exists. It is assumed that there are no restrictions.
+	// This is a "full allow" for crawling. Note: this includes 401
+	// "Unauthorized" and 403 "Forbidden" HTTP result codes.
+	ClientError: StatusAllowAll,
+
+	// From Google's spec:
+	// Server errors (5xx) are seen as temporary errors that result in a "full
+	// disallow" of crawling.
+	ServerError: StatusDisallowAll,
+}
+
+// GoogleStatusPolicy returns the interpretation of Google's current
+// documentation: redirects that were not followed to a file count as a
+// missing file, 4xx allow everything except 429 "Too Many Requests", which
+// is a server error, and server errors make the site unreachable.
+func GoogleStatusPolicy() StatusPolicy {
+	return StatusPolicy{
+		Success:     StatusParse,
+		Redirect:    StatusAllowAll,
+		ClientError: StatusAllowAll,
+		ServerError: StatusUnreachable,
+		Overrides:   map[int]StatusAction{http.StatusTooManyRequests: StatusUnreachable},
+
+		NetworkError: StatusUnreachable,
+	}
+}
+
+// RFC9309StatusPolicy returns the interpretation of RFC 9309 section 2.3.1:
+// the file is unavailable, which allows everything, on 4xx responses and
+// after too many redirects, and unreachable on 5xx responses and network
+// errors.
+func RFC9309StatusPolicy() StatusPolicy {
+	return StatusPolicy{
+		Success:     StatusParse,
+		Redirect:    StatusAllowAll,
+		ClientError: StatusAllowAll,
+		ServerError: StatusUnreachable,
+
+		NetworkError: StatusUnreachable,
+	}
+}
+
+// ConservativeStatusPolicy returns a policy for crawlers that rather skip a
+// site than crawl it against the owner's will: 401 and 403 disallow
+// everything, 429, unresolved redirects and server errors make the site
+// unreachable, and only other 4xx allow everything.
+func ConservativeStatusPolicy() StatusPolicy {
+	return StatusPolicy{
+		Success:     StatusParse,
+		Redirect:    StatusUnreachable,
+		ClientError: StatusAllowAll,
+		ServerError: StatusUnreachable,
+		Overrides: map[int]StatusAction{
+			http.StatusUnauthorized:    StatusDisallowAll,
+			http.StatusForbidden:       StatusDisallowAll,
+			http.StatusTooManyRequests: StatusUnreachable,
+		},
+
+		NetworkError: StatusUnreachable,
+	}
+}
+
+// Override returns a copy of p with action for statusCode.
+func (p StatusPolicy) Override(statusCode int, action StatusAction) StatusPolicy {
+	overrides := make(map[int]StatusAction, len(p.Overrides)+1)
+	for code, a := range p.Overrides {
+		overrides[code] = a
+	}
+	overrides[statusCode] = action
+	p.Overrides = overrides
+	return p
+}
+
+// Action returns the action for statusCode.
+func (p StatusPolicy) Action(statusCode int) StatusAction {
+	if a, ok := p.Overrides[statusCode]; ok {
+		return a
+	}
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return p.Success
+	case statusCode >= 300 && statusCode < 400:
+		return p.Redirect
+	case statusCode >= 400 && statusCode < 500:
+		return p.ClientError
+	case statusCode >= 500 && statusCode < 600:
+		return p.ServerError
+	}
+	return StatusUnexpected
+}
+
+// FromStatusAndBytesWithPolicy interprets a robots.txt response with the
+// given status policy.
+func FromStatusAndBytesWithPolicy(statusCode int, body []byte, policy StatusPolicy) (*RobotsData, error) {
+	return fromStatus(statusCode, policy, func() (*RobotsData, error) { return FromBytes(body) })
+}
+
+// FromResponseWithPolicy is like FromResponse with the given status policy.
+func FromResponseWithPolicy(res *http.Response, policy StatusPolicy) (*RobotsData, error) {
+	if res == nil {
+		// Edge case, if res is nil, return nil data
+		return nil, nil
+	}
+	return fromResponse(res, res.Body, policy, ParseOptions{})
+}
+
+// fromResponse interprets res, reading the robots.txt file from body.
+func fromResponse(res *http.Response, body io.Reader, policy StatusPolicy, opts ParseOptions) (*RobotsData, error) {
+	r, err := fromStatus(res.StatusCode, policy, func() (*RobotsData, error) {
+		return FromReader(body, opts)
+	})
+	if r != nil {
+		r.setResponse(res, time.Now())
+	}
+	return r, err
+}
+
+func fromStatus(statusCode int, policy StatusPolicy, parse func() (*RobotsData, error)) (*RobotsData, error) {
+	action := policy.Action(statusCode)
+	if action == StatusParse {
+		return parse()
+	}
+	if r := fromAction(action); r != nil {
+		return r, nil
+	}
+	return nil, errors.New("Unexpected status: " + strconv.Itoa(statusCode))
+}
+
+// fromAction returns the result of an action that does not look at the body,
+// or nil for StatusParse and StatusUnexpected. Every result is a new value,
+// so that fields set on one, like Origin, do not show up in others.
+func fromAction(action StatusAction) *RobotsData {
+	switch action {
+	case StatusAllowAll:
+		return &RobotsData{allowAll: true}
+	case StatusDisallowAll:
+		return &RobotsData{disallowAll: true}
+	case StatusUnreachable:
+		return &RobotsData{disallowAll: true, unreachable: true}
+	}
+	return nil
+}
+
+// Unreachable reports whether r stands for a robots.txt file that could not
+// be fetched because of a temporary failure. Everything is disallowed then,
+// but a previously fetched copy may be used instead.
+func (r *RobotsData) Unreachable() bool {
+	return r.unreachable
+}
@@ -0,0 +1,102 @@
+package robotstxt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusPolicies(t *testing.T) {
+	t.Parallel()
+	policies := map[string]StatusPolicy{
+		"default":      defaultStatusPolicy,
+		"google":       GoogleStatusPolicy(),
+		"rfc9309":      RFC9309StatusPolicy(),
+		"conservative": ConservativeStatusPolicy(),
+	}
+	expect := map[string]map[int]StatusAction{
+		"default": {
+			100: StatusUnexpected, 200: StatusParse, 301: StatusUnexpected, 401: StatusAllowAll, 403: StatusAllowAll,
+			404: StatusAllowAll, 429: StatusAllowAll, 500: StatusDisallowAll, 503: StatusDisallowAll, 600: StatusUnexpected,
+		},
+		"google": {
+			100: StatusUnexpected, 200: StatusParse, 301: StatusAllowAll, 401: StatusAllowAll, 403: StatusAllowAll,
+			404: StatusAllowAll, 429: StatusUnreachable, 500: StatusUnreachable, 503: StatusUnreachable, 600: StatusUnexpected,
+		},
+		"rfc9309": {
+			100: StatusUnexpected, 200: StatusParse, 301: StatusAllowAll, 401: StatusAllowAll, 403: StatusAllowAll,
+			404: StatusAllowAll, 429: StatusAllowAll, 500: StatusUnreachable, 503: StatusUnreachable, 600: StatusUnexpected,
+		},
+		"conservative": {
+			100: StatusUnexpected, 200: StatusParse, 301: StatusUnreachable, 401: StatusDisallowAll, 403: StatusDisallowAll,
+			404: StatusAllowAll, 429: StatusUnreachable, 500: StatusUnreachable, 503: StatusUnreachable, 600: StatusUnexpected,
+		},
+	}
+	for name, p := range policies {
+		for code, action := range expect[name] {
+			assert.Equal(t, action, p.Action(code), "%s %d", name, code)
+		}
+		if name != "default" {
+			assert.Equal(t, StatusUnreachable, p.NetworkError, name)
+		}
+	}
+}
+
+func TestStatusPolicyOverride(t *testing.T) {
+	t.Parallel()
+	google := GoogleStatusPolicy()
+	p := google.Override(401, StatusDisallowAll).Override(403, StatusDisallowAll).Override(304, StatusParse)
+	assert.Equal(t, StatusDisallowAll, p.Action(401))
+	assert.Equal(t, StatusDisallowAll, p.Action(403))
+	assert.Equal(t, StatusUnreachable, p.Action(429))
+	assert.Equal(t, StatusParse, p.Action(304))
+	// The original is not modified.
+	assert.Equal(t, StatusAllowAll, google.Action(401))
+	assert.Equal(t, StatusUnexpected, StatusPolicy{}.Action(200))
+}
+
+func TestFromStatusAndBytesWithPolicy(t *testing.T) {
+	t.Parallel()
+	p := ConservativeStatusPolicy()
+
+	r, err := FromStatusAndBytesWithPolicy(200, []byte("User-agent: *\nDisallow: /private"), p)
+	require.NoError(t, err)
+	expectAllAgents(t, r, false, "/private")
+	expectAllAgents(t, r, true, "/public")
+	assert.False(t, r.Unreachable())
+
+	r, err = FromStatusAndBytesWithPolicy(403, []byte("Forbidden"), p)
+	require.NoError(t, err)
+	expectAll(t, r, false)
+	assert.False(t, r.Unreachable())
+
+	r, err = FromStatusAndBytesWithPolicy(404, nil, p)
+	require.NoError(t, err)
+	expectAll(t, r, true)
+
+	r, err = FromStatusAndBytesWithPolicy(429, nil, p)
+	require.NoError(t, err)
+	expectAll(t, r, false)
+	assert.True(t, r.Unreachable())
+	assert.True(t, r.Explain("/", "bot").DisallowAll)
+
+	buf, err := r.MarshalJSON()
+	require.NoError(t, err)
+	restored := &RobotsData{}
+	require.NoError(t, restored.UnmarshalJSON(buf))
+	assert.True(t, restored.Unreachable())
+	expectAll(t, restored, false)
+
+	_, err = FromStatusAndBytesWithPolicy(101, nil, p)
+	assert.EqualError(t, err, "Unexpected status: 101")
+
+	r, err = FromResponseWithPolicy(newHttpResponse(503, ""), GoogleStatusPolicy())
+	require.NoError(t, err)
+	assert.True(t, r.Unreachable())
+	assert.Equal(t, 503, r.StatusCode)
+
+	r, err = FromResponseWithPolicy(newHttpResponse(301, ""), GoogleStatusPolicy())
+	require.NoError(t, err)
+	expectAll(t, r, true)
+}
@@ -0,0 +1,232 @@
+package robotstxt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotStored is returned by Store.Get when there is no record for an origin.
+var ErrNotStored = errors.New("robotstxt: not stored")
+
+// Record is a fetched robots.txt file as kept by a Store. The raw body is
+// kept rather than the parsed rules, so records are parsed again by the
+// current version of this package when they are loaded.
+type Record struct {
+	Origin       string    `json:"origin"` // As in "https://example.com"
+	StatusCode   int       `json:"status_code"`
+	Body         []byte    `json:"body"` // Without Content-Encoding
+	FetchedAt    time.Time `json:"fetched_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	CacheControl string    `json:"cache_control,omitempty"`
+}
+
+// Parse interprets the record with policy and opts, like a fresh response.
+func (rec *Record) Parse(policy StatusPolicy, opts ParseOptions) (*RobotsData, error) {
+	u, err := RobotsURL(rec.Origin)
+	if err != nil {
+		return nil, err
+	}
+	r, err := fromStatus(rec.StatusCode, policy, func() (*RobotsData, error) {
+		return FromReader(bytes.NewReader(rec.Body), opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.Origin = u
+	r.FetchedAt = rec.FetchedAt
+	r.StatusCode = rec.StatusCode
+	r.ETag = rec.ETag
+	r.LastModified = rec.LastModified
+	r.CacheControl = rec.CacheControl
+	return r, nil
+}
+
+func (rec *Record) clone() *Record {
+	c := *rec
+	c.Body = append([]byte(nil), rec.Body...)
+	return &c
+}
+
+// Store keeps robots.txt records by origin, so that a Client does not have
+// to fetch every file again after a restart. Implementations must be safe
+// for concurrent use.
+type Store interface {
+	// Get returns the record of origin, or ErrNotStored.
+	Get(ctx context.Context, origin string) (*Record, error)
+	// Put adds or replaces the record of rec.Origin.
+	Put(ctx context.Context, rec *Record) error
+	// Delete removes the record of origin, if any.
+	Delete(ctx context.Context, origin string) error
+}
+
+// MemoryStore is a Store in memory. The zero value is empty and usable.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func (s *MemoryStore) Get(ctx context.Context, origin string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[origin]
+	if !ok {
+		return nil, ErrNotStored
+	}
+	return rec.clone(), nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records == nil {
+		s.records = make(map[string]*Record)
+	}
+	s.records[rec.Origin] = rec.clone()
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, origin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, origin)
+	return nil
+}
+
+// fileVersion is the version of the FileStore format.
+const fileVersion = 1
+
+// Names of FileStore files.
+const (
+	fileSuffix = ".json"
+	tempPrefix = ".tmp-"
+)
+
+// FileStore is a Store in a directory, one JSON file per origin. Files are
+// written to a temporary file first and renamed, so readers and crashes
+// never see partial records.
+type FileStore struct {
+	dir string
+}
+
+type fileRecord struct {
+	Version int `json:"version"`
+	*Record
+}
+
+// NewFileStore returns a FileStore in dir, which is created if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path returns the file of origin. Origins are hashed, they may contain
+// characters that are not allowed in file names.
+func (s *FileStore) path(origin string) string {
+	sum := sha256.Sum256([]byte(origin))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+fileSuffix)
+}
+
+func (s *FileStore) Get(ctx context.Context, origin string) (*Record, error) {
+	rec, err := readRecord(s.path(origin))
+	if errors.Is(err, os.ErrNotExist) || (err == nil && rec.Origin != origin) {
+		return nil, ErrNotStored
+	}
+	return rec, err
+}
+
+func (s *FileStore) Put(ctx context.Context, rec *Record) error {
+	data, err := json.Marshal(fileRecord{Version: fileVersion, Record: rec})
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(rec.Origin))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (s *FileStore) Delete(ctx context.Context, origin string) error {
+	err := os.Remove(s.path(origin))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Compact removes records fetched before the given time, records that
+// cannot be read or have another format version, and temporary files left
+// by interrupted writes before that time. It returns the number of files
+// removed.
+func (s *FileStore) Compact(before time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, e := range entries {
+		name := e.Name()
+		path := filepath.Join(s.dir, name)
+		switch {
+		case e.IsDir():
+			continue
+		case strings.HasPrefix(name, tempPrefix):
+			info, err := e.Info()
+			if err != nil || !info.ModTime().Before(before) {
+				continue
+			}
+		case strings.HasSuffix(name, fileSuffix):
+			rec, err := readRecord(path)
+			if err == nil && !rec.FetchedAt.Before(before) {
+				continue
+			}
+		default:
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func readRecord(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fr := fileRecord{Record: &Record{}}
+	if err := json.Unmarshal(data, &fr); err != nil {
+		return nil, err
+	}
+	if fr.Version != fileVersion {
+		return nil, errors.New("robotstxt: unsupported store version in " + path)
+	}
+	return fr.Record, nil
+}
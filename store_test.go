@@ -0,0 +1,112 @@
+package robotstxt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	_, err := s.Get(ctx, "https://example.com")
+	assert.True(t, errors.Is(err, ErrNotStored))
+
+	rec := &Record{
+		Origin:     "https://example.com",
+		StatusCode: 200,
+		Body:       []byte(robotsFetched),
+		FetchedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ETag:       `"v1"`,
+	}
+	require.NoError(t, s.Put(ctx, rec))
+	rec.Body[0] = 'X' // Stores keep their own copy
+	got, err := s.Get(ctx, "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, robotsFetched, string(got.Body))
+	assert.Equal(t, `"v1"`, got.ETag)
+	assert.True(t, rec.FetchedAt.Equal(got.FetchedAt))
+	_, err = s.Get(ctx, "http://example.com")
+	assert.True(t, errors.Is(err, ErrNotStored))
+
+	require.NoError(t, s.Delete(ctx, "https://example.com"))
+	require.NoError(t, s.Delete(ctx, "https://example.com"))
+	_, err = s.Get(ctx, "https://example.com")
+	assert.True(t, errors.Is(err, ErrNotStored))
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+	testStore(t, &MemoryStore{})
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	s, err := NewFileStore(filepath.Join(dir, "robots"))
+	require.NoError(t, err)
+	testStore(t, s)
+
+	files, err := os.ReadDir(filepath.Join(dir, "robots"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFileStoreCompact(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, s.Put(ctx, &Record{Origin: "https://old.example", FetchedAt: now.Add(-48 * time.Hour)}))
+	require.NoError(t, s.Put(ctx, &Record{Origin: "https://new.example", FetchedAt: now}))
+	broken := filepath.Join(dir, "0123.json")
+	require.NoError(t, os.WriteFile(broken, []byte(`{"version": 0}`), 0o644))
+	temp := filepath.Join(dir, tempPrefix+"1")
+	require.NoError(t, os.WriteFile(temp, []byte("{"), 0o644))
+	require.NoError(t, os.Chtimes(temp, now.Add(-48*time.Hour), now.Add(-48*time.Hour)))
+	other := filepath.Join(dir, "README")
+	require.NoError(t, os.WriteFile(other, nil, 0o644))
+
+	removed, err := s.Compact(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	_, err = s.Get(ctx, "https://old.example")
+	assert.True(t, errors.Is(err, ErrNotStored))
+	_, err = s.Get(ctx, "https://new.example")
+	assert.NoError(t, err)
+	_, err = os.Stat(other)
+	assert.NoError(t, err)
+}
+
+func TestRecordParse(t *testing.T) {
+	t.Parallel()
+	rec := &Record{
+		Origin:       "https://example.com",
+		StatusCode:   200,
+		Body:         []byte(robotsFetched),
+		FetchedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CacheControl: "max-age=60",
+	}
+	r, err := rec.Parse(RFC9309StatusPolicy(), ParseOptions{})
+	require.NoError(t, err)
+	assert.False(t, r.TestAgent("/private", "bot"))
+	assert.Equal(t, "https://example.com/robots.txt", r.Origin.String())
+	assert.Equal(t, rec.FetchedAt, r.FetchedAt)
+	age, _ := r.MaxAge()
+	assert.Equal(t, time.Minute, age)
+
+	rec.StatusCode = 404
+	r, err = rec.Parse(RFC9309StatusPolicy(), ParseOptions{})
+	require.NoError(t, err)
+	assert.True(t, r.TestAgent("/private", "bot"))
+	_, err = rec.Parse(ConservativeStatusPolicy().Override(404, StatusUnexpected), ParseOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package robotstxt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrOtherOrigin is returned by TestURL for URLs whose scheme, host or port
+// differ from the Origin of the robots.txt file. The rules of a file only
+// apply to the service it was fetched from, see RFC 9309 section 2.3.
+var ErrOtherOrigin = errors.New("robotstxt: URL is not from the origin of the robots.txt file")
+
+// TestURL is like TestAgent for a full URL. The path and the query are
+// matched against the rules, as Google does, and the fragment is ignored. An
+// empty path is the same as "/". If r knows its Origin, absolute URLs from
+// other origins are rejected with ErrOtherOrigin.
+func (r *RobotsData) TestURL(u *url.URL, agent string) (bool, error) {
+	if u.Opaque != "" {
+		return false, fmt.Errorf("robotstxt: URL %q has no path", u.String())
+	}
+	if r.Origin != nil && u.Host != "" && origin(u) != origin(r.Origin) {
+		return false, fmt.Errorf("%w: %s is not on %s", ErrOtherOrigin, origin(u), origin(r.Origin))
+	}
+	return r.TestAgent(requestURI(u), agent), nil
+}
+
+// TestURLString parses rawURL and calls TestURL.
+func (r *RobotsData) TestURLString(rawURL, agent string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+	return r.TestURL(u, agent)
+}
+
+// requestURI returns the part of u that robots.txt rules are matched with:
+// the escaped path, "/" if it is empty, and the query.
+func requestURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" || u.ForceQuery {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
+
+// origin returns the scheme, host and port of u in canonical form, with the
+// default port of the scheme left out.
+func origin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == defaultPort(scheme) {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return scheme + "://" + host
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	case "ftp":
+		return "21"
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package robotstxt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the usage preference directives. Content-Signal is the policy
+// published by Cloudflare, as in "Content-Signal: search=yes, ai-train=no".
+// Content-Usage is the IETF aipref attachment, as in
+// "Content-Usage: /blog/ train-ai=n". Both may start with a path pattern
+// that limits them.
+const (
+	ContentSignal = "Content-Signal"
+	ContentUsage  = "Content-Usage"
+)
+
+// Preference is a site's preference for a category of use of its content.
+type Preference int
+
+const (
+	// PreferenceUnset means no preference was stated.
+	PreferenceUnset Preference = iota
+	// PreferenceAllow is stated as "yes" or "y".
+	PreferenceAllow
+	// PreferenceDisallow is stated as "no" or "n".
+	PreferenceDisallow
+)
+
+func (p Preference) String() string {
+	switch p {
+	case PreferenceUnset:
+		return "unset"
+	case PreferenceAllow:
+		return "allow"
+	case PreferenceDisallow:
+		return "disallow"
+	}
+	return fmt.Sprintf("Preference(%d)", int(p))
+}
+
+type usageRule struct {
+	directive string                // ContentSignal or ContentUsage
+	rule      *rule                 // Path pattern, nil if the line applies to all paths
+	prefs     map[string]Preference // By category in lower case
+	value     string                // Value as written
+	line      int
+}
+
+// UsageRule describes a Content-Signal or Content-Usage line of a group, see
+// Group.UsageRules.
+type UsageRule struct {
+	Directive string // ContentSignal or ContentUsage
+	// Pattern is the path pattern the line is limited to, in the same form
+	// as Rule.Pattern, or "" if it applies to all paths.
+	Pattern     string
+	Preferences map[string]Preference // By category in lower case
+	Line        int
+}
+
+// parseUsage parses the value of a usage preference directive: an optional
+// path pattern, then "category=value" items separated by commas. Items that
+// cannot be understood are passed to warn and skipped.
+func parseUsage(directive, val string, warn func(msg string)) (*usageRule, error) {
+	u := &usageRule{directive: directive, prefs: make(map[string]Preference), value: val}
+	items := val
+	if strings.HasPrefix(val, "/") || strings.HasPrefix(val, "*") {
+		path := val
+		items = ""
+		if i := strings.IndexFunc(val, isSpace); i >= 0 {
+			path, items = val[:i], val[i:]
+		}
+		li, err := parsePathVal(lUsage, directive, path)
+		if err != nil {
+			return nil, err
+		}
+		u.rule = &rule{path: li.vs, pattern: li.vr, raw: li.raw}
+	}
+
+	for _, item := range strings.Split(items, ",") {
+		item = strings.TrimFunc(item, isSpace)
+		if item == "" {
+			continue
+		}
+		// Parameters of structured field items, as in "train-ai=n;x=1",
+		// are not used.
+		if i := strings.IndexByte(item, ';'); i >= 0 {
+			item = item[:i]
+		}
+		i := strings.IndexByte(item, '=')
+		if i < 0 {
+			warn(fmt.Sprintf("%s item %q has no value", directive, item))
+			continue
+		}
+		category := strings.ToLower(strings.TrimFunc(item[:i], isSpace))
+		switch v := strings.ToLower(strings.Trim(strings.TrimFunc(item[i+1:], isSpace), `"`)); v {
+		case "yes", "y":
+			u.prefs[category] = PreferenceAllow
+		case "no", "n":
+			u.prefs[category] = PreferenceDisallow
+		default:
+			warn(fmt.Sprintf("%s value %q of %q is neither yes nor no", directive, v, category))
+		}
+	}
+	return u, nil
+}
+
+// UsageRules returns the Content-Signal and Content-Usage lines of the group
+// in source order.
+func (g *Group) UsageRules() []UsageRule {
+	rules := make([]UsageRule, 0, len(g.usageRules))
+	for _, u := range g.usageRules {
+		ur := UsageRule{Directive: u.directive, Preferences: make(map[string]Preference, len(u.prefs)), Line: u.line}
+		if u.rule != nil {
+			ur.Pattern = u.rule.text()
+		}
+		for k, v := range u.prefs {
+			ur.Preferences[k] = v
+		}
+		rules = append(rules, ur)
+	}
+	return rules
+}
+
+// UsagePreference returns the preference of the site for category, as in
+// "ai-train" or "search", of the content at path for agent. Of the lines of
+// the agent's group that state the category and match the path, the one
+// with the longest path pattern wins, ranked as Test ranks rules. Lines
+// without a pattern rank lowest. If equally long lines disagree, "no" wins.
+// Categories are compared as written: "ai-train" of Content-Signal is not
+// "train-ai" of Content-Usage.
+func (r *RobotsData) UsagePreference(path, agent, category string) Preference {
+	if r.allowAll || r.disallowAll {
+		return PreferenceUnset
+	}
+	return r.FindGroup(agent).UsagePreference(path, category)
+}
+
+// UsagePreference is like RobotsData.UsagePreference for the group.
+func (g *Group) UsagePreference(path, category string) Preference {
+	path = normalizePath(path)
+	category = strings.ToLower(category)
+	pref, best := PreferenceUnset, -1
+	for _, u := range g.usageRules {
+		p, ok := u.prefs[category]
+		if !ok {
+			continue
+		}
+		l := 0
+		if u.rule != nil {
+			if l, ok = g.matchLength(u.rule, path); !ok {
+				continue
+			}
+		}
+		if l > best || (l == best && p == PreferenceDisallow) {
+			pref, best = p, l
+		}
+	}
+	return pref
+}
@@ -0,0 +1,108 @@
+package robotstxt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const robotsUsage = `User-agent: *
+Content-Signal: search=yes, ai-train=no
+Content-Signal: /blog/ ai-train=yes
+Content-Signal: /blog/private/ ai-train=no, ai-input=no
+Content-Usage: train-ai=n
+Content-Usage: /open/*.html train-ai=y
+Disallow: /admin
+
+User-agent: friendbot
+Content-Signal: ai-train=yes
+Content-Signal: /x ai-train=yes
+Content-Signal: /x ai-train=no
+
+User-agent: sloppy
+content-signal: ai-train, search=maybe, ai-input = "n" ;v=1
+`
+
+func TestUsagePreference(t *testing.T) {
+	t.Parallel()
+	r, diags, err := Parse([]byte(robotsUsage))
+	require.NoError(t, err)
+
+	cases := []struct {
+		path, agent, category string
+		expected              Preference
+	}{
+		{"/", "bot", "search", PreferenceAllow},
+		{"/", "bot", "ai-train", PreferenceDisallow},
+		{"/blog/post", "bot", "ai-train", PreferenceAllow},
+		{"/blog/post", "bot", "AI-Train", PreferenceAllow},
+		{"/blog/private/a", "bot", "ai-train", PreferenceDisallow},
+		{"/blog/private/a", "bot", "ai-input", PreferenceDisallow},
+		{"/blog/post", "bot", "ai-input", PreferenceUnset},
+		{"/blog/post", "bot", "search", PreferenceAllow},
+		{"/", "bot", "train-ai", PreferenceDisallow},
+		{"/open/a.html", "bot", "train-ai", PreferenceAllow},
+		{"/open/a.pdf", "bot", "train-ai", PreferenceDisallow},
+		{"/", "bot", "other", PreferenceUnset},
+		{"/", "friendbot", "ai-train", PreferenceAllow},
+		{"/", "friendbot", "search", PreferenceUnset},
+		// Equally long lines that disagree: no wins.
+		{"/x", "friendbot", "ai-train", PreferenceDisallow},
+		{"/", "sloppy", "ai-input", PreferenceDisallow},
+		{"/", "sloppy", "search", PreferenceUnset},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, r.UsagePreference(c.path, c.agent, c.category), "%s %s %s", c.path, c.agent, c.category)
+	}
+
+	// Usage lines are group members, they do not affect Test.
+	assert.False(t, r.TestAgent("/admin", "bot"))
+	assert.True(t, r.TestAgent("/blog", "bot"))
+
+	require.Len(t, diags, 2)
+	assert.Equal(t, CodeInvalidUsage, diags[0].Code)
+	assert.Equal(t, 15, diags[0].Line)
+
+	rules := r.FindGroup("bot").UsageRules()
+	require.Len(t, rules, 5)
+	assert.Equal(t, UsageRule{ContentSignal, "/blog/private/", map[string]Preference{"ai-train": PreferenceDisallow, "ai-input": PreferenceDisallow}, 4}, rules[2])
+	assert.Equal(t, UsageRule{ContentUsage, "", map[string]Preference{"train-ai": PreferenceDisallow}, 5}, rules[3])
+
+	assert.Equal(t, PreferenceUnset, (&RobotsData{disallowAll: true}).UsagePreference("/", "bot", "search"))
+	assert.Equal(t, "disallow", PreferenceDisallow.String())
+}
+
+func TestUsagePreferenceRFC9309(t *testing.T) {
+	t.Parallel()
+	r, err := FromString("User-agent: *\nContent-Signal: /*.pdf ai-train=no\nContent-Signal: /docs/a ai-train=yes\n")
+	require.NoError(t, err)
+	// The legacy ranking uses the regexp length of wildcard patterns.
+	assert.Equal(t, PreferenceDisallow, r.UsagePreference("/docs/a.pdf", "bot", "ai-train"))
+	r.SetMatchMode(MatchRFC9309)
+	assert.Equal(t, PreferenceAllow, r.UsagePreference("/docs/a.pdf", "bot", "ai-train"))
+}
+
+func TestUsageWriteAndJSON(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(robotsUsage)
+	require.NoError(t, err)
+	assert.Equal(t, `User-agent: friendbot
+Content-Signal: ai-train=yes
+Content-Signal: /x ai-train=yes
+Content-Signal: /x ai-train=no
+`, r.FindGroup("friendbot").String())
+
+	r2, err := FromString(r.String())
+	require.NoError(t, err)
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	r3 := &RobotsData{}
+	require.NoError(t, json.Unmarshal(data, r3))
+	for _, r := range []*RobotsData{r2, r3} {
+		assert.Equal(t, PreferenceAllow, r.UsagePreference("/blog/post", "bot", "ai-train"))
+		assert.Equal(t, PreferenceDisallow, r.UsagePreference("/blog/private/", "bot", "ai-input"))
+	}
+	assert.Equal(t, 4, r3.FindGroup("bot").UsageRules()[2].Line)
+}
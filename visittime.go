@@ -0,0 +1,119 @@
+package robotstxt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// TimeWindow is a daily period in UTC from a Visit-time line, as offsets
+// from midnight. Start is included and End is not. End is before Start when
+// the window wraps past midnight, as in "2300-0400".
+type TimeWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Contains reports whether the UTC time of day of t falls in w.
+func (w TimeWindow) Contains(t time.Time) bool {
+	tod := timeOfDay(t)
+	if w.Start <= w.End {
+		return tod >= w.Start && tod < w.End
+	}
+	return tod >= w.Start || tod < w.End
+}
+
+// String returns w in robots.txt form, as in "0100-0545".
+func (w TimeWindow) String() string {
+	return formatClock(w.Start) + "-" + formatClock(w.End)
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// parseTimeWindow parses "HHMM-HHMM", colons between hours and minutes are
+// allowed. The end may be "2400".
+func parseTimeWindow(val string) (TimeWindow, error) {
+	i := strings.IndexByte(val, '-')
+	if i < 0 {
+		return TimeWindow{}, errors.New("no \"-\" between start and end")
+	}
+	start, err := parseClock(val[:i])
+	if err != nil || start == day {
+		return TimeWindow{}, errors.New("start is not a time of day")
+	}
+	end, err := parseClock(val[i+1:])
+	if err != nil {
+		return TimeWindow{}, errors.New("end is not a time of day")
+	}
+	if start == end {
+		return TimeWindow{}, errors.New("window is empty")
+	}
+	return TimeWindow{Start: start, End: end}, nil
+}
+
+// parseClock parses "HHMM" or "HH:MM", up to "2400".
+func parseClock(s string) (time.Duration, error) {
+	s = strings.Replace(strings.TrimSpace(s), ":", "", 1)
+	if len(s) != 4 {
+		return 0, errors.New("not HHMM")
+	}
+	h, err := strconv.ParseUint(s[:2], 10, 8)
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.ParseUint(s[2:], 10, 8)
+	if err != nil {
+		return 0, err
+	}
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	if m > 59 || d > day {
+		return 0, errors.New("out of range")
+	}
+	return d, nil
+}
+
+// AllowedAt reports whether the group's agents may visit at t, that is
+// whether t falls in one of the VisitTimes, or the group has none.
+func (g *Group) AllowedAt(t time.Time) bool {
+	if len(g.VisitTimes) == 0 {
+		return true
+	}
+	for _, w := range g.VisitTimes {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextAllowed returns the earliest time from t on at which AllowedAt is
+// true: t itself, or the start of the next window.
+func (g *Group) NextAllowed(t time.Time) time.Time {
+	if g.AllowedAt(t) {
+		return t
+	}
+	tod := timeOfDay(t)
+	wait := day
+	for _, w := range g.VisitTimes {
+		d := w.Start - tod
+		if d < 0 {
+			d += day
+		}
+		if d < wait {
+			wait = d
+		}
+	}
+	return t.Add(wait)
+}
@@ -0,0 +1,98 @@
+package robotstxt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	t.Parallel()
+	valid := map[string]TimeWindow{
+		"0100-0545":     {time.Hour, 5*time.Hour + 45*time.Minute},
+		"23:00 - 04:00": {23 * time.Hour, 4 * time.Hour},
+		"0000-2400":     {0, 24 * time.Hour},
+		"1800-0000":     {18 * time.Hour, 0},
+	}
+	for val, expect := range valid {
+		w, err := parseTimeWindow(val)
+		if assert.NoError(t, err, val) {
+			assert.Equal(t, expect, w, val)
+		}
+	}
+	for _, val := range []string{"", "0100", "100-0545", "0160-0200", "2500-0100", "2400-0100", "0100-2401", "0100-0100", "ab00-0100", "+100-0200"} {
+		_, err := parseTimeWindow(val)
+		assert.Error(t, err, val)
+	}
+	assert.Equal(t, "2300-0400", TimeWindow{23 * time.Hour, 4 * time.Hour}.String())
+}
+
+func TestVisitTime(t *testing.T) {
+	t.Parallel()
+	r, diags, err := Parse([]byte(`User-agent: night
+Visit-time: 2300-0400
+Visit-time: 1200-1230
+
+User-agent: day
+Visittime: 0900-1700
+Visit-time: 9-17
+
+User-agent: any
+Disallow: /private
+`))
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, CodeInvalidVisitTime, diags[0].Code)
+	assert.Equal(t, 7, diags[0].Line)
+
+	at := func(hour, min int) time.Time { return time.Date(2024, 3, 10, hour, min, 0, 0, time.UTC) }
+	night := r.FindGroup("night")
+	require.Len(t, night.VisitTimes, 2)
+	cases := []struct {
+		t       time.Time
+		allowed bool
+		next    time.Time
+	}{
+		{at(22, 59), false, at(23, 0)},
+		{at(23, 0), true, at(23, 0)},
+		{at(23, 59), true, at(23, 59)},
+		{at(0, 0), true, at(0, 0)},
+		{at(3, 59), true, at(3, 59)},
+		{at(4, 0), false, at(12, 0)},
+		{at(12, 15), true, at(12, 15)},
+		{at(12, 30), false, at(23, 0)},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.allowed, night.AllowedAt(c.t), "%v", c.t)
+		assert.Equal(t, c.next, night.NextAllowed(c.t), "%v", c.t)
+	}
+
+	// Windows are in UTC, the result keeps the location of t.
+	zone := time.FixedZone("UTC+3", 3*3600)
+	day := r.FindGroup("day")
+	local := time.Date(2024, 3, 10, 21, 30, 0, 0, zone) // 18:30 UTC
+	assert.False(t, day.AllowedAt(local))
+	next := day.NextAllowed(local)
+	assert.True(t, next.Equal(time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, zone, next.Location())
+
+	// Groups without windows may visit any time.
+	assert.True(t, r.FindGroup("any").AllowedAt(at(4, 0)))
+	assert.Equal(t, at(4, 0), r.FindGroup("any").NextAllowed(at(4, 0)))
+
+	assert.Equal(t, "User-agent: night\nVisit-time: 2300-0400\nVisit-time: 1200-1230\n", night.String())
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	r2 := &RobotsData{}
+	require.NoError(t, json.Unmarshal(data, r2))
+	assert.Equal(t, night.VisitTimes, r2.FindGroup("night").VisitTimes)
+
+	built, err := NewBuilder().Group("*").VisitTime(23*time.Hour, 4*time.Hour).Build()
+	require.NoError(t, err)
+	assert.Equal(t, []TimeWindow{{23 * time.Hour, 4 * time.Hour}}, built.FindGroup("x").VisitTimes)
+	_, err = NewBuilder().Group("*").VisitTime(time.Hour, 25*time.Hour).Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package robotstxt
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteTo writes r as robots.txt text in canonical form: one group per
+// user-agent, specific agents in alphabetical order followed by "*", rules
+// in their original order and pattern form, then Host, Sitemaps and global
+// extensions. Unknown directives are left out, as it is not known where
+// they belong. Parsing the output again gives the same decisions as r once
+// the caller settings are applied again: the MatchMode of groups, see
+// SetMatchMode, and the AgentMatchMode of r are not part of the text.
+func (r *RobotsData) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+
+	switch {
+	case r.allowAll:
+		// An empty file allows everything.
+	case r.disallowAll:
+		writeLine(&b, "User-agent", AnyGroupId)
+		writeLine(&b, "Disallow", "/")
+	default:
+		agents := make([]string, 0, len(r.groups))
+		for a := range r.groups {
+			if a != AnyGroupId {
+				agents = append(agents, a)
+			}
+		}
+		sort.Strings(agents)
+		if _, ok := r.groups[AnyGroupId]; ok {
+			agents = append(agents, AnyGroupId)
+		}
+		for i, a := range agents {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			r.groups[a].writeGroup(&b, a)
+		}
+	}
+
+	if r.Host != "" || len(r.Sitemaps) > 0 || len(r.extensions) > 0 {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		if r.Host != "" {
+			writeLine(&b, "Host", r.Host)
+		}
+		for _, s := range r.Sitemaps {
+			writeLine(&b, "Sitemap", s)
+		}
+		for _, d := range r.extensions {
+			writeLine(&b, d.Name, d.Value)
+		}
+	}
+
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// String returns r as robots.txt text, see WriteTo.
+func (r *RobotsData) String() string {
+	var b strings.Builder
+	_, _ = r.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo writes g as a robots.txt group for its Agent.
+func (g *Group) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	g.writeGroup(&b, g.Agent)
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// String returns g as a robots.txt group, see WriteTo.
+func (g *Group) String() string {
+	var b strings.Builder
+	_, _ = g.WriteTo(&b)
+	return b.String()
+}
+
+func (g *Group) writeGroup(b *bytes.Buffer, agent string) {
+	writeLine(b, "User-agent", agent)
+	members := 0
+	for _, r := range g.rules {
+		if r.allow {
+			writeLine(b, "Allow", r.text())
+		} else {
+			writeLine(b, "Disallow", r.text())
+		}
+		members++
+	}
+	if g.CrawlDelay > 0 {
+		writeLine(b, "Crawl-delay", strconv.FormatFloat(g.CrawlDelay.Seconds(), 'f', -1, 64))
+		members++
+	}
+	if g.RequestRate.Requests > 0 {
+		writeLine(b, "Request-rate", g.RequestRate.String())
+		members++
+	}
+	for _, w := range g.VisitTimes {
+		writeLine(b, "Visit-time", w.String())
+		members++
+	}
+	for _, u := range g.usageRules {
+		writeLine(b, u.directive, u.value)
+		members++
+	}
+	for _, d := range g.extensions {
+		writeLine(b, d.Name, d.Value)
+		members++
+	}
+	for _, r := range g.cleanParamRules {
+		v := strings.Join(r.params, "&")
+		if p := r.text(); p != "" {
+			v += " " + p
+		}
+		writeLine(b, "Clean-param", v)
+		members++
+	}
+	// Without a member line the next User-agent would join this group.
+	if members == 0 {
+		writeLine(b, "Disallow", "")
+	}
+}
+
+func writeLine(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	b.WriteByte(':')
+	if value != "" {
+		b.WriteByte(' ')
+		b.WriteString(value)
+	}
+	b.WriteByte('\n')
+}
+
+// text returns the path pattern of the rule as it was written.
+func (r *rule) text() string {
+	switch {
+	case r.raw != "":
+		return r.raw
+	case r.pattern != nil:
+		// Rules restored from JSON of older versions only have the regexp.
+		return unquotePattern(r.pattern.String())
+	}
+	return r.path
+}
+
+func (r *cleanParamRule) text() string {
+	switch {
+	case r.raw != "":
+		return r.raw
+	case r.pattern != nil:
+		return unquotePattern(r.pattern.String())
+	}
+	return r.path
+}
+
+// unquotePattern turns a regexp compiled from a path pattern back into the
+// pattern, reversing regexp.QuoteMeta and the wildcard replacements.
+func unquotePattern(expr string) string {
+	var b strings.Builder
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\\' && i+1 < len(expr):
+			i++
+			b.WriteByte(expr[i])
+		case c == '.' && i+1 < len(expr) && expr[i+1] == '*':
+			i++
+			b.WriteByte('*')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package robotstxt
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTo(t *testing.T) {
+	t.Parallel()
+	r, err := FromString(`Sitemap: https://example.com/sitemap.xml
+User-agent: Googlebot
+User-agent: Bingbot
+Disallow: /private
+Allow: /private/open*
+Disallow: /*.pdf$
+Crawl-delay: 2.5
+
+User-agent: *
+Disallow: /tmp   # temporary
+Clean-param: ref&utm /forum/*.php
+Clean-param: sid
+Host: example.com`)
+	require.NoError(t, err)
+
+	const expect = `User-agent: bingbot
+Disallow: /private
+Allow: /private/open*
+Disallow: /*.pdf$
+Crawl-delay: 2.5
+
+User-agent: googlebot
+Disallow: /private
+Allow: /private/open*
+Disallow: /*.pdf$
+Crawl-delay: 2.5
+
+User-agent: *
+Disallow: /tmp
+Clean-param: ref&utm /forum/*.php
+Clean-param: sid
+
+Host: example.com
+Sitemap: https://example.com/sitemap.xml
+`
+	var b bytes.Buffer
+	n, err := r.WriteTo(&b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(expect)), n)
+	assert.Equal(t, expect, b.String())
+	assert.Equal(t, expect, r.String())
+
+	assert.Equal(t, "User-agent: *\nDisallow: /tmp\nClean-param: ref&utm /forum/*.php\nClean-param: sid\n", r.FindGroup("other").String())
+}
+
+func TestWriteToSpecialStates(t *testing.T) {
+	t.Parallel()
+	r, err := FromStatusAndBytes(404, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", r.String())
+	r, err = FromStatusAndBytes(503, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "User-agent: *\nDisallow: /\n", r.String())
+
+	r, err = FromString("User-agent: a\nUser-agent: b\nDisallow:\n")
+	require.NoError(t, err)
+	assert.Equal(t, "User-agent: a\nDisallow:\n\nUser-agent: b\nDisallow:\n", r.String())
+}
+
+func TestWriteToRoundTrip(t *testing.T) {
+	t.Parallel()
+	inputs := map[string]string{
+		"001":        robotsText001,
+		"google":     robotsGoogle,
+		"matching":   robotsCaseMatching,
+		"precedence": robotsCasePrecedence,
+		"grouping":   "user-agent: a\nuser-agent: b\ndisallow: /a\ndisallow: /b\n\nuser-agent: ignore\nDisallow: /separator\n\nuser-agent: b\nuser-agent: c\ndisallow: /b\ndisallow: /c",
+		"vanityfair": robotsTextVanityfair,
+		"encoding":   "User-agent: *\nDisallow: /café\nAllow: /%63afe/menu\nDisallow: /my file",
+		"delays":     "User-agent: a\nCrawl-delay: 0.25\nUser-agent: b\nCrawl-delay: 100\nDisallow: /",
+		"rates":      "User-agent: a\nRequest-rate: 1/5\nUser-agent: b\nRequest-rate: 30/1m\nDisallow: /",
+		"visits":     "User-agent: a\nVisit-time: 2300-0400\nVisit-time: 12:00-12:30\nUser-agent: b\nDisallow: /",
+	}
+	agents := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "ignore", "Googlebot", "Yandex", "other"}
+	paths := []string{"", "/", "/a", "/b", "/c", "/fish", "/fish.html", "/fishheads/catfish.php", "/filename.php?x",
+		"/page", "/page.htm", "/folder/page", "/search", "/news/directory", "/places/", "/toolkit/a.html",
+		"/foo/oroscopo-di-oggi/bar", "/café", "/cafe/menu", "/my file", "/administrator/", "/index.php?option=com_phorum,quote=1"}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			r, err := FromString(input)
+			require.NoError(t, err)
+			text := r.String()
+			r2, err := FromString(text)
+			require.NoError(t, err, text)
+
+			for _, a := range agents {
+				for _, p := range paths {
+					assert.Equal(t, r.TestAgent(p, a), r2.TestAgent(p, a), "agent %s path %q\n%s", a, p, text)
+				}
+				assert.Equal(t, r.FindGroup(a).CrawlDelay, r2.FindGroup(a).CrawlDelay)
+				assert.Equal(t, r.FindGroup(a).RequestRate, r2.FindGroup(a).RequestRate)
+				assert.Equal(t, r.FindGroup(a).VisitTimes, r2.FindGroup(a).VisitTimes)
+			}
+			assert.Equal(t, r.Sitemaps, r2.Sitemaps)
+			assert.Equal(t, r.Host, r2.Host)
+			// Canonical form is stable.
+			assert.Equal(t, text, r2.String())
+		})
+	}
+}
+
+func TestWriteToModes(t *testing.T) {
+	t.Parallel()
+	r, err := FromString("User-agent: foo\nDisallow: /page\nAllow: /p\n\nUser-agent: *\nDisallow: /\n")
+	require.NoError(t, err)
+	r.SetMatchMode(MatchRFC9309)
+	r.AgentMatchMode = AgentMatchPrefix
+	assert.False(t, r.TestAgent("/page", "FooBarBot"))
+
+	// The text does not keep the modes, the parsed copy has the defaults.
+	r2, err := FromString(r.String())
+	require.NoError(t, err)
+	assert.Equal(t, MatchLegacy, r2.FindGroup("foo").MatchMode)
+	assert.Equal(t, AgentMatchToken, r2.AgentMatchMode)
+	assert.True(t, r.TestAgent("/p", "FooBarBot"))
+	assert.False(t, r2.TestAgent("/p", "FooBarBot"))
+
+	// Applied again, they give the same decisions.
+	r2.SetMatchMode(MatchRFC9309)
+	r2.AgentMatchMode = AgentMatchPrefix
+	for _, a := range []string{"foo", "FooBarBot", "FooBot/1.0", "other"} {
+		for _, p := range []string{"/", "/p", "/page", "/pages", "/x"} {
+			assert.Equal(t, r.TestAgent(p, a), r2.TestAgent(p, a), "agent %s path %s", a, p)
+		}
+	}
+}
+
+func TestWriteToFromJSON(t *testing.T) {
+	t.Parallel()
+	r := &RobotsData{}
+	require.NoError(t, r.UnmarshalJSON([]byte(`{"groups":{"*":{"agent":"*","crawl_delay":2000000000,"rules":[
+		{"allow":false,"path":"","pattern":"/.*\\.pdf$"},
+		{"allow":true,"path":"/public","pattern":""}]}}}`)))
+	assert.Equal(t, "User-agent: *\nDisallow: /*.pdf$\nAllow: /public\nCrawl-delay: 2\n", r.String())
+	assert.Equal(t, 2*time.Second, r.FindGroup("x").CrawlDelay)
+}